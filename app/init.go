@@ -2,11 +2,25 @@ package app
 
 import (
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/joho/godotenv"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/apikey"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/db"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/idempotency"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/subscription"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
 )
 
 func InitializeApp() chi.Router {
@@ -19,11 +33,103 @@ func InitializeApp() chi.Router {
 	database := db.ConnectDB()
 	db.Migrate(database)
 
+	rates := currency.NewStaticRateProvider(currency.DefaultCode)
+	if ratesFile := os.Getenv("EXCHANGE_RATES_FILE"); ratesFile != "" {
+		rates, err = currency.LoadRateFile(ratesFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to load exchange rates: %v", err)
+		}
+		log.Println("💱 Exchange rates loaded successfully")
+	}
+
+	tenantRepo := tenant.NewTenantRepository(database)
+	tenantService := tenant.NewTenantService(tenantRepo)
+	tenantHandler := tenant.NewTenantHandler(tenantService)
+
+	userRepo := user.NewUserRepository(database, subscription.TrashUserSubscriptions)
+	userService := user.NewUserService(userRepo, tenantService)
+	userHandler := user.NewUserHandler(userService)
+
 	subRepo := subscription.NewSubscriptionRepository(database)
-	subService := subscription.NewSubscriptionService(subRepo)
-	subHandler := subscription.NewSubscriptionHandler(subService)
+	auditRepo := audit.NewAuditRepository(database)
+	auditService := audit.NewAuditService(auditRepo)
+	auditHandler := audit.NewAuditHandler(auditService)
+
+	catalogRepo := catalog.NewCatalogRepository(database)
+	catalogService := catalog.NewCatalogService(catalogRepo)
+	catalogHandler := catalog.NewCatalogHandler(catalogService)
+
+	categoryRepo := category.NewCategoryRepository(database)
+	categoryService := category.NewCategoryService(categoryRepo)
+	categoryHandler := category.NewCategoryHandler(categoryService)
+
+	tagRepo := tag.NewTagRepository(database)
+	tagService := tag.NewTagService(tagRepo)
+	tagHandler := tag.NewTagHandler(tagService)
+
+	subService := subscription.NewSubscriptionService(subRepo, rates, auditService, catalogService, categoryService, userService)
+	requireIfMatch := true
+	if value := os.Getenv("REQUIRE_IF_MATCH"); value != "" {
+		requireIfMatch, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("❌ Invalid REQUIRE_IF_MATCH value: %v", err)
+		}
+	}
+	subHandler := subscription.NewSubscriptionHandler(subService, requireIfMatch)
+
+	retentionDays := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		retentionDays, err = strconv.Atoi(value)
+		if err != nil || retentionDays < 0 {
+			log.Fatalf("❌ Invalid TRASH_RETENTION_DAYS value: %q", value)
+		}
+	}
+	if retentionDays > 0 {
+		subscription.StartTrashRetention(subService, time.Duration(retentionDays)*24*time.Hour, time.Hour)
+	}
+
+	apiKeyRepo := apikey.NewAPIKeyRepository(database)
+	apiKeyService := apikey.NewAPIKeyService(apiKeyRepo)
+	apiKeyHandler := apikey.NewAPIKeyHandler(apiKeyService)
+
+	idempotencyTTL := 24 * time.Hour
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		idempotencyTTL, err = time.ParseDuration(value)
+		if err != nil || idempotencyTTL <= 0 {
+			log.Fatalf("❌ Invalid IDEMPOTENCY_TTL value: %q", value)
+		}
+	}
+	idempotencyRepo := idempotency.NewIdempotencyRepository(database)
+	idempotency.StartCleanup(idempotencyRepo, time.Hour)
+
+	jwtAuthenticator, err := middleware.NewJWTAuthenticatorFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure authentication: %v", err)
+	}
+
+	policy := rbac.DefaultPolicy()
+	if policyFile := os.Getenv("RBAC_POLICY_FILE"); policyFile != "" {
+		policy, err = rbac.LoadPolicyFile(policyFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to load RBAC policy: %v", err)
+		}
+		log.Println("🛡️ RBAC policy loaded successfully")
+	}
 
-	router := NewRouter(subHandler)
+	router := NewRouter(
+		subHandler,
+		apiKeyHandler,
+		auditHandler,
+		catalogHandler,
+		categoryHandler,
+		tagHandler,
+		userHandler,
+		tenantHandler,
+		middleware.Authenticate(jwtAuthenticator, apiKeyService),
+		middleware.ResolveTenant(userService.Membership),
+		policy,
+		idempotency.Middleware(idempotencyRepo, idempotencyTTL),
+	)
 
 	log.Println("✅ Application initialized successfully")
 	return router
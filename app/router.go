@@ -1,16 +1,40 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/qwerty2265/go-chi-subscription-manager/docs" // путь к docs, если docs в корне
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/apikey"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/subscription"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(subscriptionHandler *subscription.SubscriptionHandler) chi.Router {
+func NewRouter(
+	subscriptionHandler *subscription.SubscriptionHandler,
+	apiKeyHandler *apikey.APIKeyHandler,
+	auditHandler *audit.AuditHandler,
+	catalogHandler *catalog.CatalogHandler,
+	categoryHandler *category.CategoryHandler,
+	tagHandler *tag.TagHandler,
+	userHandler *user.UserHandler,
+	tenantHandler *tenant.TenantHandler,
+	authenticate func(http.Handler) http.Handler,
+	resolveTenant func(http.Handler) http.Handler,
+	policy *rbac.Policy,
+	idempotent func(http.Handler) http.Handler,
+) chi.Router {
 	r := chi.NewRouter()
 
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Logger)
 
@@ -19,7 +43,17 @@ func NewRouter(subscriptionHandler *subscription.SubscriptionHandler) chi.Router
 	))
 
 	r.Route("/api", func(r chi.Router) {
-		r.Mount("/subscriptions", subscription.SubscriptionRouter(*subscriptionHandler))
+		r.Use(authenticate)
+		// API key responses carry secrets, so they are never stored for
+		// idempotent retries.
+		r.With(idempotent, resolveTenant).Mount("/subscriptions", subscription.SubscriptionRouter(*subscriptionHandler, policy))
+		r.With(idempotent).Mount("/catalog", catalog.CatalogRouter(*catalogHandler, policy, resolveTenant))
+		r.With(idempotent, resolveTenant).Mount("/categories", category.CategoryRouter(*categoryHandler, policy))
+		r.With(idempotent, resolveTenant).Mount("/tags", tag.TagRouter(*tagHandler, policy))
+		r.With(idempotent, resolveTenant).Mount("/users", user.UserRouter(*userHandler, policy))
+		r.With(idempotent).Mount("/organizations", tenant.TenantRouter(*tenantHandler, policy, resolveTenant, user.MemberRouter(*userHandler, policy), user.ProvisionRouter(*userHandler)))
+		r.With(resolveTenant).Mount("/api-keys", apikey.APIKeyRouter(*apiKeyHandler, policy))
+		r.With(resolveTenant).Mount("/audit", audit.AuditRouter(*auditHandler, policy))
 	})
 
 	return r
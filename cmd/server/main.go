@@ -8,6 +8,15 @@ import (
 	"github.com/qwerty2265/go-chi-subscription-manager/app"
 )
 
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT bearer token: "Bearer {token}"
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Server-to-server API key: "ApiKey {key}"
 func main() {
 	router := app.InitializeApp()
 	serverPort := os.Getenv("SERVER_PORT")
@@ -0,0 +1,13 @@
+package apikey
+
+type APIKeyCreateDTO struct {
+	Name   string   `json:"name" validate:"required,notblank,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read write admin"`
+}
+
+// IssuedAPIKeyDTO is returned when a key is issued or rotated. It is the only
+// time the plaintext key is available.
+type IssuedAPIKeyDTO struct {
+	APIKey
+	Key string `json:"key"`
+}
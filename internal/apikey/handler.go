@@ -0,0 +1,187 @@
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type APIKeyHandler struct {
+	apiKeyService APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// -------------------- handler methods ----------------
+
+// IssueAPIKey godoc
+// @Summary      Issue API key
+// @Description  Issues a new API key with the given scopes (read, write, admin) that acts for users of the organization only; the plaintext key is only returned once
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        apiKey   body      APIKeyCreateDTO  true  "API key data"
+// @Param        user-id  query     string           false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/api-keys [post]
+func (h *APIKeyHandler) IssueAPIKey(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	var apiKey APIKeyCreateDTO
+	if err := common.DecodeJSON(w, r, &apiKey); err != nil {
+		return err
+	}
+
+	issuedKey, err := h.apiKeyService.IssueAPIKey(r.Context(), membership.TenantID, &apiKey)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "API key issued",
+		Data:    issuedKey,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetAllAPIKeys godoc
+// @Summary      List API keys
+// @Description  Returns all API keys issued in the organization without their secrets
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/api-keys [get]
+func (h *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.apiKeyService.GetAllAPIKeys(r.Context(), membership.TenantID)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    keys,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RotateAPIKey godoc
+// @Summary      Rotate API key
+// @Description  Replaces the secret of an API key; the previous secret stops working immediately
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "API key ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseAPIKeyID(r)
+	if err != nil {
+		return err
+	}
+
+	rotatedKey, err := h.apiKeyService.RotateAPIKey(r.Context(), membership.TenantID, id)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "API key rotated",
+		Data:    rotatedKey,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  Revokes an API key so it can no longer authenticate
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "API key ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseAPIKeyID(r)
+	if err != nil {
+		return err
+	}
+
+	revokedKey, err := h.apiKeyService.RevokeAPIKey(r.Context(), membership.TenantID, id)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "API key revoked",
+		Data:    revokedKey,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseAPIKeyID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "API key ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid API key ID format")
+	}
+	return id, nil
+}
@@ -0,0 +1,88 @@
+package apikey
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+)
+
+// APIKey is a credential for server-to-server clients. Only the SHA-256 hash
+// of the secret is stored; Prefix keeps enough of it to tell keys apart.
+// TenantID is the organization the key was issued in and may act in.
+type APIKey struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	TenantID   uuid.UUID      `gorm:"type:uuid;not null;index;<-:create" json:"tenant_id"`
+	Tenant     *tenant.Tenant `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Name       string         `gorm:"not null" json:"name"`
+	Prefix     string         `gorm:"not null" json:"prefix"`
+	Hash       string         `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     Scopes         `gorm:"type:text;not null" json:"scopes"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (k *APIKey) Validate() error {
+	var fields []apperror.FieldError
+
+	if strings.TrimSpace(k.Name) == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name is required"})
+	}
+
+	if len(k.Scopes) == 0 {
+		fields = append(fields, apperror.FieldError{Field: "scopes", Message: "at least one scope is required"})
+	}
+	for _, scope := range k.Scopes {
+		switch scope {
+		case middleware.ScopeRead, middleware.ScopeWrite, middleware.ScopeAdmin:
+		default:
+			fields = append(fields, apperror.FieldError{
+				Field:   "scopes",
+				Message: fmt.Sprintf("invalid scope %q (expected read, write or admin)", scope),
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields[0].Message, fields...)
+	}
+	return nil
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Scopes is stored as a comma separated list.
+type Scopes []string
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+func (s *Scopes) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into Scopes", value)
+	}
+
+	*s = nil
+	for _, scope := range strings.Split(raw, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			*s = append(*s, scope)
+		}
+	}
+	return nil
+}
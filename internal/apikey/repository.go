@@ -0,0 +1,73 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+	GetAllAPIKeys(ctx context.Context, tenantId uuid.UUID) ([]APIKey, error)
+	GetAPIKeyByID(ctx context.Context, tenantId, id uuid.UUID) (*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	UpdateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, apperror.Database(err, "API key")
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetAllAPIKeys(ctx context.Context, tenantId uuid.UUID) ([]APIKey, error) {
+	var keys []APIKey
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantId).Order("created_at").Find(&keys).Error; err != nil {
+		return nil, apperror.Database(err, "API key")
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, tenantId, id uuid.UUID) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, "id = ? AND tenant_id = ?", id, tenantId).Error; err != nil {
+		return nil, apperror.Database(err, "API key")
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, "hash = ?", hash).Error; err != nil {
+		return nil, apperror.Database(err, "API key")
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) UpdateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	if err := r.db.WithContext(ctx).Save(key).Error; err != nil {
+		return nil, apperror.Database(err, "API key")
+	}
+	return key, nil
+}
+
+// TouchAPIKey records when a key was last used without bumping updated_at.
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	return apperror.Database(err, "API key")
+}
@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// APIKeyRouter manages the API keys of the tenant resolved by
+// middleware.ResolveTenant for clients with the admin scope whose role policy
+// grants rbac.AdminAPIKeys.
+func APIKeyRouter(apiKeyHandler APIKeyHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequireScope(middleware.ScopeAdmin))
+	r.Use(middleware.RequirePermission(policy, rbac.AdminAPIKeys))
+
+	r.Post("/", middleware.ErrorWrapper(apiKeyHandler.IssueAPIKey))
+	r.Get("/", middleware.ErrorWrapper(apiKeyHandler.GetAllAPIKeys))
+	r.Post("/{id}/rotate", middleware.ErrorWrapper(apiKeyHandler.RotateAPIKey))
+	r.Delete("/{id}", middleware.ErrorWrapper(apiKeyHandler.RevokeAPIKey))
+
+	return r
+}
@@ -0,0 +1,158 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+)
+
+const (
+	keyPrefix = "sm_"
+	// lastUsedResolution limits how often authentication writes last_used_at.
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyService interface {
+	middleware.Authenticator
+	IssueAPIKey(ctx context.Context, tenantId uuid.UUID, apiKey *APIKeyCreateDTO) (*IssuedAPIKeyDTO, error)
+	GetAllAPIKeys(ctx context.Context, tenantId uuid.UUID) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, tenantId, id uuid.UUID) (*APIKey, error)
+	RotateAPIKey(ctx context.Context, tenantId, id uuid.UUID) (*IssuedAPIKeyDTO, error)
+}
+
+type apiKeyService struct {
+	repo APIKeyRepository
+}
+
+func NewAPIKeyService(repo APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+// IssueAPIKey issues a key that acts for users of the tenant tenantId only.
+func (s *apiKeyService) IssueAPIKey(ctx context.Context, tenantId uuid.UUID, apiKey *APIKeyCreateDTO) (*IssuedAPIKeyDTO, error) {
+	if err := validation.Struct(apiKey); err != nil {
+		return nil, err
+	}
+
+	key := &APIKey{
+		ID:       uuid.New(),
+		TenantID: tenantId,
+		Name:     apiKey.Name,
+		Scopes:   apiKey.Scopes,
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	key.Prefix, key.Hash = secret[:len(keyPrefix)+6], hashSecret(secret)
+
+	createdKey, err := s.repo.CreateAPIKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedAPIKeyDTO{APIKey: *createdKey, Key: secret}, nil
+}
+
+func (s *apiKeyService) GetAllAPIKeys(ctx context.Context, tenantId uuid.UUID) ([]APIKey, error) {
+	return s.repo.GetAllAPIKeys(ctx, tenantId)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, tenantId, id uuid.UUID) (*APIKey, error) {
+	key, err := s.repo.GetAPIKeyByID(ctx, tenantId, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	return s.repo.UpdateAPIKey(ctx, key)
+}
+
+// RotateAPIKey replaces the secret of a key, keeping its id, name and scopes.
+// The previous secret stops working immediately.
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, tenantId, id uuid.UUID) (*IssuedAPIKeyDTO, error) {
+	key, err := s.repo.GetAPIKeyByID(ctx, tenantId, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return nil, apperror.Conflict("cannot rotate a revoked API key")
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	key.Prefix, key.Hash = secret[:len(keyPrefix)+6], hashSecret(secret)
+
+	updatedKey, err := s.repo.UpdateAPIKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedAPIKeyDTO{APIKey: *updatedKey, Key: secret}, nil
+}
+
+// -------------------------- authenticator methods --------------------------
+
+func (s *apiKeyService) Scheme() string {
+	return "ApiKey"
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, credentials string) (*middleware.Principal, error) {
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashSecret(credentials))
+	if err != nil {
+		if apperror.KindOf(err) == apperror.KindNotFound {
+			return nil, apperror.Unauthorized("unknown API key")
+		}
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return nil, apperror.Unauthorized("API key has been revoked")
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &middleware.Principal{
+		Type:     middleware.PrincipalAPIKey,
+		Subject:  key.ID.String(),
+		TenantID: key.TenantID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// -------------------------- helpers --------------------------
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
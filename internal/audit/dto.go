@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditQueryDTO filters audit entries. Zero values leave a filter out.
+type AuditQueryDTO struct {
+	ResourceType string
+	ResourceID   uuid.UUID
+	OwnerID      uuid.UUID
+	ActorID      string
+	Action       Action
+	From         time.Time
+	To           time.Time
+	Limit        int
+	Cursor       string
+}
+
+type AuditPageDTO struct {
+	Items      []AuditEntry `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+type AuditHandler struct {
+	auditService AuditService
+}
+
+func NewAuditHandler(auditService AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// -------------------- handler methods ----------------
+
+// ListEntries godoc
+// @Summary      Query audit log
+// @Description  Returns audit entries of the organization's resources, newest first, filtered by resource, owner, actor, action and time; pass next_cursor back as cursor to get the next page
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Param        resource-type  query     string  false "Resource type, e.g. subscription"
+// @Param        resource-id    query     string  false "Resource ID"
+// @Param        owner-id       query     string  false "ID of the user owning the resource"
+// @Param        actor-id       query     string  false "Token subject or API key ID of the actor"
+// @Param        action         query     string  false "create, update, delete, restore or purge"
+// @Param        from           query     string  false "Only entries at or after this time (RFC 3339)"
+// @Param        to             query     string  false "Only entries before this time (RFC 3339)"
+// @Param        limit          query     int     false "Page size (1-200, default 50)"
+// @Param        cursor         query     string  false "Cursor returned by the previous page"
+// @Param        user-id        query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/audit [get]
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) error {
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.auditService.ListEntries(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    page,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseAuditQuery(r *http.Request) (*AuditQueryDTO, error) {
+	values := r.URL.Query()
+	query := &AuditQueryDTO{
+		ResourceType: values.Get("resource-type"),
+		ActorID:      values.Get("actor-id"),
+		Action:       Action(values.Get("action")),
+		Cursor:       values.Get("cursor"),
+	}
+
+	switch query.Action {
+	case "", ActionCreate, ActionUpdate, ActionDelete, ActionRestore, ActionPurge:
+	default:
+		return nil, apperror.InvalidField("action", "invalid action (expected create, update, delete, restore or purge)")
+	}
+
+	for param, dst := range map[string]*uuid.UUID{"resource-id": &query.ResourceID, "owner-id": &query.OwnerID} {
+		if value := values.Get(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, apperror.InvalidField(param, "invalid "+param+" format")
+			}
+			*dst = id
+		}
+	}
+
+	for param, dst := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := values.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, apperror.InvalidField(param, "invalid "+param+" time (expected RFC 3339)")
+			}
+			*dst = t
+		}
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return nil, apperror.InvalidField("limit", "invalid limit value")
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+	ActionPurge   Action = "purge"
+)
+
+// ActorSystem identifies changes made by background jobs rather than requests.
+const ActorSystem = "system"
+
+// AuditEntry records one change of a resource: who made it, in which request,
+// and the fields it changed. OwnerID is the user the resource belongs to.
+type AuditEntry struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	ResourceType string    `gorm:"not null;index:idx_audit_entries_resource" json:"resource_type"`
+	ResourceID   uuid.UUID `gorm:"type:uuid;not null;index:idx_audit_entries_resource" json:"resource_id"`
+	OwnerID      uuid.UUID `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Action       Action    `gorm:"not null" json:"action"`
+	ActorType    string    `gorm:"not null" json:"actor_type"`
+	ActorID      string    `gorm:"not null;index" json:"actor_id"`
+	RequestID    string    `json:"request_id,omitempty"`
+	Changes      Changes   `gorm:"type:jsonb;not null" json:"changes"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index;<-:create" json:"created_at"`
+}
+
+// FieldChange holds the JSON values of a field before and after a change.
+// A null side means the field was absent, e.g. before a create.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Changes maps JSON field names to their changes and is stored as JSON.
+type Changes map[string]FieldChange
+
+func (c Changes) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *Changes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	default:
+		return fmt.Errorf("cannot scan type %T into Changes", value)
+	}
+}
+
+// ignoredFields are recorded on the entry itself or change on every write, so
+// they would only add noise to the diff.
+var ignoredFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+}
+
+// NewEntry builds an audit entry for a change made in ctx. before and after
+// are the JSON representations of the resource, nil when it did not exist.
+func NewEntry(ctx context.Context, resourceType string, resourceID, ownerID uuid.UUID, action Action, before, after interface{}) (*AuditEntry, error) {
+	changes, err := diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &AuditEntry{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OwnerID:      ownerID,
+		Action:       action,
+		ActorType:    ActorSystem,
+		ActorID:      ActorSystem,
+		RequestID:    chimiddleware.GetReqID(ctx),
+		Changes:      changes,
+	}
+	if principal, ok := middleware.PrincipalFromContext(ctx); ok {
+		entry.ActorType = string(principal.Type)
+		entry.ActorID = principal.Subject
+	}
+	return entry, nil
+}
+
+// diff compares the top-level JSON fields of two values.
+func diff(before, after interface{}) (Changes, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(Changes)
+	for name, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[name]) {
+			changes[name] = FieldChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = FieldChange{Before: nil, After: value}
+		}
+	}
+	for name := range changes {
+		if ignoredFields[name] {
+			delete(changes, name)
+		}
+	}
+	return changes, nil
+}
+
+func fields(value interface{}) (map[string]json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name, v := range fields {
+		if string(v) == "null" {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// EntryCursor points right after the last entry of a page. Entries are listed
+// newest first.
+type EntryCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func newEntryCursor(last *AuditEntry) (string, error) {
+	cursor, err := json.Marshal(EntryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+func decodeEntryCursor(value string) (*EntryCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, apperror.InvalidField("cursor", "invalid cursor")
+	}
+
+	var cursor EntryCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, apperror.InvalidField("cursor", "invalid cursor")
+	}
+	return &cursor, nil
+}
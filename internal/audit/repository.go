@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	CreateEntry(ctx context.Context, entry *AuditEntry) error
+	ListEntries(ctx context.Context, query *AuditQueryDTO, after *EntryCursor) ([]AuditEntry, error)
+}
+
+// ownerTenantCondition restricts entries to resources owned by users of one
+// tenant.
+const ownerTenantCondition = "owner_id IN (SELECT id FROM users WHERE tenant_id = ?)"
+
+var errMissingTenant = errors.New("audit query without a tenant")
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a repository on db. Pass a transaction to write
+// entries atomically with the change they record.
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *auditRepository) CreateEntry(ctx context.Context, entry *AuditEntry) error {
+	err := r.db.WithContext(ctx).Create(entry).Error
+	return apperror.Database(err, "audit entry")
+}
+
+// ListEntries returns up to query.Limit matching entries of the tenant ctx
+// acts in, newest first, starting after the cursor.
+func (r *auditRepository) ListEntries(ctx context.Context, query *AuditQueryDTO, after *EntryCursor) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	db := r.scoped(ctx)
+
+	if query.ResourceType != "" {
+		db = db.Where("resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceID != uuid.Nil {
+		db = db.Where("resource_id = ?", query.ResourceID)
+	}
+	if query.OwnerID != uuid.Nil {
+		db = db.Where("owner_id = ?", query.OwnerID)
+	}
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	if after != nil {
+		db = db.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&entries).Error
+	if err != nil {
+		return nil, apperror.Database(err, "audit entry")
+	}
+	return entries, nil
+}
+
+// -------------------------- helpers --------------------------
+
+// scoped returns a handle restricted to the entries of the tenant ctx acts
+// in. Queries without a tenant fail, unless ctx was marked by
+// tenancy.WithoutTenant.
+func (r *auditRepository) scoped(ctx context.Context) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if tenancy.IsUnscoped(ctx) {
+		return db
+	}
+
+	membership, ok := tenancy.FromContext(ctx)
+	if !ok {
+		db.AddError(errMissingTenant)
+		return db
+	}
+	return db.Where(ownerTenantCondition, membership.TenantID)
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// AuditRouter serves the audit log of the tenant resolved by
+// middleware.ResolveTenant to clients with the admin scope whose role policy
+// grants rbac.AdminAudit.
+func AuditRouter(auditHandler AuditHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequireScope(middleware.ScopeAdmin))
+	r.Use(middleware.RequirePermission(policy, rbac.AdminAudit))
+
+	r.Get("/", middleware.ErrorWrapper(auditHandler.ListEntries))
+
+	return r
+}
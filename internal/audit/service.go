@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+)
+
+type AuditService interface {
+	ListEntries(ctx context.Context, query *AuditQueryDTO) (*AuditPageDTO, error)
+}
+
+type auditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+// ListEntries returns one page of matching entries and, when more remain, the
+// cursor of the next page.
+func (s *auditService) ListEntries(ctx context.Context, query *AuditQueryDTO) (*AuditPageDTO, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maxPageSize {
+		query.Limit = maxPageSize
+	}
+
+	after, err := decodeEntryCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	pageQuery := *query
+	pageQuery.Limit++
+	entries, err := s.repo.ListEntries(ctx, &pageQuery, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AuditPageDTO{Items: entries}
+	if len(entries) > query.Limit {
+		page.Items = entries[:query.Limit]
+		page.NextCursor, err = newEntryCursor(&page.Items[query.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
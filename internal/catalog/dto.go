@@ -0,0 +1,11 @@
+package catalog
+
+// CatalogEntryDTO creates a catalog entry or replaces one entirely.
+type CatalogEntryDTO struct {
+	Name            string   `json:"name" validate:"required,notblank,max=255"`
+	Aliases         []string `json:"aliases,omitempty" validate:"max=50,dive,notblank,max=255"`
+	Category        string   `json:"category,omitempty" validate:"max=100"`
+	Website         string   `json:"website,omitempty" validate:"omitempty,url,max=255"`
+	DefaultCurrency string   `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+	DefaultPrice    *int     `json:"default_price,omitempty" validate:"omitnil,min=0,max=100000000"`
+}
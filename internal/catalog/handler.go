@@ -0,0 +1,200 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+type CatalogHandler struct {
+	catalogService CatalogService
+}
+
+func NewCatalogHandler(catalogService CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+// -------------------- handler methods ----------------
+
+// CreateEntry godoc
+// @Summary      Create catalog entry
+// @Description  Adds a known service with its canonical name, aliases, category, website and default currency and price; requires the system scope
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Param        entry    body      CatalogEntryDTO  true  "Catalog entry data"
+// @Success      201  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Router       /api/catalog [post]
+func (h *CatalogHandler) CreateEntry(w http.ResponseWriter, r *http.Request) error {
+	var entry CatalogEntryDTO
+	if err := common.DecodeJSON(w, r, &entry); err != nil {
+		return err
+	}
+
+	createdEntry, err := h.catalogService.CreateEntry(r.Context(), &entry)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "catalog entry created",
+		Data:    createdEntry,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListEntries godoc
+// @Summary      List catalog entries
+// @Description  Returns the service catalog ordered by name
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Param        category  query     string  false "Only entries of this category"
+// @Param        user-id   query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/catalog [get]
+func (h *CatalogHandler) ListEntries(w http.ResponseWriter, r *http.Request) error {
+	entries, err := h.catalogService.ListEntries(r.Context(), r.URL.Query().Get("category"))
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetEntryByID godoc
+// @Summary      Get catalog entry
+// @Description  Returns a catalog entry by ID
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Catalog entry ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/catalog/{id} [get]
+func (h *CatalogHandler) GetEntryByID(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseEntryID(r)
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.catalogService.GetEntryByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    entry,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// UpdateEntry godoc
+// @Summary      Replace catalog entry
+// @Description  Replaces every field of a catalog entry; subscriptions referencing it show the new name. Requires the system scope
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string           true  "Catalog entry ID"
+// @Param        entry    body      CatalogEntryDTO  true  "Catalog entry data"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Router       /api/catalog/{id} [put]
+func (h *CatalogHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseEntryID(r)
+	if err != nil {
+		return err
+	}
+
+	var entry CatalogEntryDTO
+	if err := common.DecodeJSON(w, r, &entry); err != nil {
+		return err
+	}
+
+	updatedEntry, err := h.catalogService.UpdateEntry(r.Context(), id, &entry)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "catalog entry updated",
+		Data:    updatedEntry,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// DeleteEntry godoc
+// @Summary      Delete catalog entry
+// @Description  Removes a catalog entry; subscriptions referencing it keep their service name as free text. Requires the system scope
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Catalog entry ID"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Router       /api/catalog/{id} [delete]
+func (h *CatalogHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseEntryID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.catalogService.DeleteEntry(r.Context(), id); err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "catalog entry deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseEntryID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "catalog entry ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid catalog entry ID format")
+	}
+	return id, nil
+}
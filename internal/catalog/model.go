@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+)
+
+// CatalogEntry is a known service provider. Subscriptions that reference an
+// entry use its Name, so spellings and translations listed in Aliases count
+// as the same service.
+type CatalogEntry struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	Name            string    `gorm:"not null" json:"name"`
+	Aliases         []string  `gorm:"type:jsonb;not null;serializer:json" json:"aliases"`
+	Category        string    `json:"category,omitempty"`
+	Website         string    `json:"website,omitempty"`
+	DefaultCurrency string    `gorm:"type:char(3)" json:"default_currency,omitempty"`
+	DefaultPrice    *int      `json:"default_price,omitempty"`
+	// MatchKeys holds the normalized name and aliases that resolve to the entry.
+	MatchKeys []string  `gorm:"type:jsonb;not null;serializer:json;index:,type:gin" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (e *CatalogEntry) Validate() error {
+	var fields []apperror.FieldError
+
+	if strings.TrimSpace(e.Name) == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name is required"})
+	}
+
+	if e.DefaultCurrency != "" {
+		if err := currency.Validate(e.DefaultCurrency); err != nil {
+			fields = append(fields, apperror.FieldError{Field: "default_currency", Message: err.Error()})
+		}
+	}
+
+	if e.DefaultPrice != nil && *e.DefaultPrice < 0 {
+		fields = append(fields, apperror.FieldError{Field: "default_price", Message: "default price cannot be negative"})
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields[0].Message, fields...)
+	}
+	return nil
+}
+
+// ReplaceFields overwrites the entry with data, normalizing names and
+// recomputing the match keys.
+func (e *CatalogEntry) ReplaceFields(data CatalogEntryDTO) {
+	e.Name = CleanName(data.Name)
+	e.Category = strings.TrimSpace(data.Category)
+	e.Website = strings.TrimSpace(data.Website)
+	e.DefaultCurrency = currency.Normalize(data.DefaultCurrency)
+	e.DefaultPrice = data.DefaultPrice
+
+	e.Aliases = []string{}
+	e.MatchKeys = []string{NormalizeName(e.Name)}
+	for _, alias := range data.Aliases {
+		alias = CleanName(alias)
+		key := NormalizeName(alias)
+		if key == "" || contains(e.MatchKeys, key) {
+			continue
+		}
+		e.Aliases = append(e.Aliases, alias)
+		e.MatchKeys = append(e.MatchKeys, key)
+	}
+}
+
+// CleanName trims a service name and collapses runs of whitespace.
+func CleanName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeName returns the key names are matched by: the cleaned name in
+// lower case.
+func NormalizeName(name string) string {
+	return strings.ToLower(CleanName(name))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+)
+
+type CatalogRepository interface {
+	CreateEntry(ctx context.Context, entry *CatalogEntry) (*CatalogEntry, error)
+	ListEntries(ctx context.Context, category string) ([]CatalogEntry, error)
+	GetEntryByID(ctx context.Context, id uuid.UUID) (*CatalogEntry, error)
+	FindEntriesByKeys(ctx context.Context, keys []string) ([]CatalogEntry, error)
+	UpdateEntry(ctx context.Context, entry *CatalogEntry) (*CatalogEntry, error)
+	DeleteEntry(ctx context.Context, id uuid.UUID) error
+}
+
+type catalogRepository struct {
+	db *gorm.DB
+}
+
+func NewCatalogRepository(db *gorm.DB) CatalogRepository {
+	return &catalogRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *catalogRepository) CreateEntry(ctx context.Context, entry *CatalogEntry) (*CatalogEntry, error) {
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return nil, apperror.Database(err, "catalog entry")
+	}
+	return entry, nil
+}
+
+func (r *catalogRepository) ListEntries(ctx context.Context, category string) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	db := r.db.WithContext(ctx)
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+	if err := db.Order("name, id").Find(&entries).Error; err != nil {
+		return nil, apperror.Database(err, "catalog entry")
+	}
+	return entries, nil
+}
+
+func (r *catalogRepository) GetEntryByID(ctx context.Context, id uuid.UUID) (*CatalogEntry, error) {
+	var entry CatalogEntry
+	if err := r.db.WithContext(ctx).First(&entry, "id = ?", id).Error; err != nil {
+		return nil, apperror.Database(err, "catalog entry")
+	}
+	return &entry, nil
+}
+
+// FindEntriesByKeys returns the entries whose name or an alias normalizes to
+// any of keys.
+func (r *catalogRepository) FindEntriesByKeys(ctx context.Context, keys []string) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	if len(keys) == 0 {
+		return entries, nil
+	}
+
+	db := r.db.WithContext(ctx)
+	conditions := db
+	for i, key := range keys {
+		value, err := json.Marshal([]string{key})
+		if err != nil {
+			return nil, apperror.Internal(err)
+		}
+		if i == 0 {
+			conditions = conditions.Where("match_keys @> ?", string(value))
+		} else {
+			conditions = conditions.Or("match_keys @> ?", string(value))
+		}
+	}
+
+	if err := db.Where(conditions).Order("name, id").Find(&entries).Error; err != nil {
+		return nil, apperror.Database(err, "catalog entry")
+	}
+	return entries, nil
+}
+
+func (r *catalogRepository) UpdateEntry(ctx context.Context, entry *CatalogEntry) (*CatalogEntry, error) {
+	if err := r.db.WithContext(ctx).Save(entry).Error; err != nil {
+		return nil, apperror.Database(err, "catalog entry")
+	}
+	return entry, nil
+}
+
+// DeleteEntry removes an entry. Subscriptions referencing it keep their
+// service name as free text.
+func (r *catalogRepository) DeleteEntry(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&CatalogEntry{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "catalog entry")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("catalog entry not found")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package catalog
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// CatalogRouter lets users read the catalog with a permission that policy
+// grants their role in the organization resolved by resolveTenant. Entries are
+// shared by every organization, so changing them requires the system scope
+// instead.
+func CatalogRouter(catalogHandler CatalogHandler, policy *rbac.Policy, resolveTenant func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+
+	read := r.With(resolveTenant, middleware.RequireScope(middleware.ScopeRead), middleware.RequirePermission(policy, rbac.CatalogRead))
+	system := r.With(middleware.RequireScope(middleware.ScopeSystem))
+
+	system.Post("/", middleware.ErrorWrapper(catalogHandler.CreateEntry))
+	read.Get("/", middleware.ErrorWrapper(catalogHandler.ListEntries))
+	read.Get("/{id}", middleware.ErrorWrapper(catalogHandler.GetEntryByID))
+	system.Put("/{id}", middleware.ErrorWrapper(catalogHandler.UpdateEntry))
+	system.Delete("/{id}", middleware.ErrorWrapper(catalogHandler.DeleteEntry))
+
+	return r
+}
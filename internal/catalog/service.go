@@ -0,0 +1,119 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+)
+
+type CatalogService interface {
+	CreateEntry(ctx context.Context, entry *CatalogEntryDTO) (*CatalogEntry, error)
+	ListEntries(ctx context.Context, category string) ([]CatalogEntry, error)
+	GetEntryByID(ctx context.Context, id uuid.UUID) (*CatalogEntry, error)
+	UpdateEntry(ctx context.Context, id uuid.UUID, entry *CatalogEntryDTO) (*CatalogEntry, error)
+	DeleteEntry(ctx context.Context, id uuid.UUID) error
+	MatchEntry(ctx context.Context, name string) (*CatalogEntry, error)
+}
+
+type catalogService struct {
+	repo CatalogRepository
+}
+
+func NewCatalogService(repo CatalogRepository) CatalogService {
+	return &catalogService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+func (s *catalogService) CreateEntry(ctx context.Context, entry *CatalogEntryDTO) (*CatalogEntry, error) {
+	if err := validation.Struct(entry); err != nil {
+		return nil, err
+	}
+
+	model := &CatalogEntry{ID: uuid.New()}
+	model.ReplaceFields(*entry)
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkUnclaimed(ctx, model); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateEntry(ctx, model)
+}
+
+func (s *catalogService) ListEntries(ctx context.Context, category string) ([]CatalogEntry, error) {
+	return s.repo.ListEntries(ctx, category)
+}
+
+func (s *catalogService) GetEntryByID(ctx context.Context, id uuid.UUID) (*CatalogEntry, error) {
+	return s.repo.GetEntryByID(ctx, id)
+}
+
+func (s *catalogService) UpdateEntry(ctx context.Context, id uuid.UUID, entry *CatalogEntryDTO) (*CatalogEntry, error) {
+	if err := validation.Struct(entry); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetEntryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.ReplaceFields(*entry)
+	if err := existing.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkUnclaimed(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateEntry(ctx, existing)
+}
+
+func (s *catalogService) DeleteEntry(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteEntry(ctx, id)
+}
+
+// MatchEntry returns the entry whose name or an alias matches name after
+// normalization, or nil when no entry does.
+func (s *catalogService) MatchEntry(ctx context.Context, name string) (*CatalogEntry, error) {
+	key := NormalizeName(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	entries, err := s.repo.FindEntriesByKeys(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+// -------------------------- helpers --------------------------
+
+// checkUnclaimed makes sure no other entry already matches the name or an
+// alias of entry, so every name resolves to a single entry.
+func (s *catalogService) checkUnclaimed(ctx context.Context, entry *CatalogEntry) error {
+	others, err := s.repo.FindEntriesByKeys(ctx, entry.MatchKeys)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == entry.ID {
+			continue
+		}
+		for _, key := range entry.MatchKeys {
+			if contains(other.MatchKeys, key) {
+				return apperror.Conflict(fmt.Sprintf("name or alias %q already belongs to catalog entry %q", key, other.Name))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package category
+
+type CategoryDTO struct {
+	Name string `json:"name" validate:"required,notblank,max=100"`
+}
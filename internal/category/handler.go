@@ -0,0 +1,193 @@
+package category
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type CategoryHandler struct {
+	categoryService CategoryService
+}
+
+func NewCategoryHandler(categoryService CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// -------------------- handler methods ----------------
+
+// CreateCategory godoc
+// @Summary      Create category
+// @Description  Creates a category for the authenticated user's subscriptions; names are unique per user ignoring case
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        category  body      CategoryDTO  true  "Category name"
+// @Param        user-id   query     string       false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/categories [post]
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var category CategoryDTO
+	if err := common.DecodeJSON(w, r, &category); err != nil {
+		return err
+	}
+
+	createdCategory, err := h.categoryService.CreateCategory(r.Context(), userId, &category)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "category created",
+		Data:    createdCategory,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListCategories godoc
+// @Summary      List categories
+// @Description  Returns the authenticated user's categories ordered by name
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/categories [get]
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	categories, err := h.categoryService.ListCategories(r.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    categories,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RenameCategory godoc
+// @Summary      Rename category
+// @Description  Renames one of the authenticated user's categories
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string       true  "Category ID"
+// @Param        category  body      CategoryDTO  true  "New category name"
+// @Param        user-id   query     string       false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/categories/{id} [put]
+func (h *CategoryHandler) RenameCategory(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseCategoryID(r)
+	if err != nil {
+		return err
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var category CategoryDTO
+	if err := common.DecodeJSON(w, r, &category); err != nil {
+		return err
+	}
+
+	renamedCategory, err := h.categoryService.RenameCategory(r.Context(), userId, id, &category)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "category renamed",
+		Data:    renamedCategory,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// DeleteCategory godoc
+// @Summary      Delete category
+// @Description  Deletes one of the authenticated user's categories; its subscriptions become uncategorized
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Category ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseCategoryID(r)
+	if err != nil {
+		return err
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.categoryService.DeleteCategory(r.Context(), userId, id); err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "category deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseCategoryID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "category ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid category ID format")
+	}
+	return id, nil
+}
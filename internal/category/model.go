@@ -0,0 +1,26 @@
+package category
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category groups a user's subscriptions, e.g. "streaming" or "cloud
+// storage". A subscription belongs to at most one category.
+type Category struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_categories_user_name;<-:create" json:"user_id"`
+	Name           string    `gorm:"not null" json:"name"`
+	NormalizedName string    `gorm:"not null;uniqueIndex:idx_categories_user_name" json:"-"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Rename sets the name, trimmed and with runs of whitespace collapsed.
+// Names are unique per user ignoring case.
+func (c *Category) Rename(name string) {
+	c.Name = strings.Join(strings.Fields(name), " ")
+	c.NormalizedName = strings.ToLower(c.Name)
+}
@@ -0,0 +1,71 @@
+package category
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *Category) (*Category, error)
+	ListCategories(ctx context.Context, userId uuid.UUID) ([]Category, error)
+	GetCategoryByID(ctx context.Context, userId, id uuid.UUID) (*Category, error)
+	UpdateCategory(ctx context.Context, category *Category) (*Category, error)
+	DeleteCategory(ctx context.Context, userId, id uuid.UUID) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, category *Category) (*Category, error) {
+	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+		return nil, apperror.Database(err, "category")
+	}
+	return category, nil
+}
+
+func (r *categoryRepository) ListCategories(ctx context.Context, userId uuid.UUID) ([]Category, error) {
+	var categories []Category
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("normalized_name").Find(&categories).Error; err != nil {
+		return nil, apperror.Database(err, "category")
+	}
+	return categories, nil
+}
+
+// GetCategoryByID returns a category owned by the user. Categories of other
+// users are reported as not found.
+func (r *categoryRepository) GetCategoryByID(ctx context.Context, userId, id uuid.UUID) (*Category, error) {
+	var category Category
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).First(&category, "id = ?", id).Error; err != nil {
+		return nil, apperror.Database(err, "category")
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, category *Category) (*Category, error) {
+	if err := r.db.WithContext(ctx).Save(category).Error; err != nil {
+		return nil, apperror.Database(err, "category")
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category; its subscriptions become uncategorized.
+func (r *categoryRepository) DeleteCategory(ctx context.Context, userId, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&Category{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "category")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("category not found")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package category
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// CategoryRouter serves the categories of the requesting user and must be
+// mounted behind middleware.ResolveTenant, since policy decides per role who
+// may read and change them.
+func CategoryRouter(categoryHandler CategoryHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	guard := func(scope string, permission rbac.Permission) chi.Router {
+		return r.With(middleware.RequireScope(scope), middleware.RequirePermission(policy, permission))
+	}
+	read := guard(middleware.ScopeRead, rbac.CategoryRead)
+	write := guard(middleware.ScopeWrite, rbac.CategoryWrite)
+
+	write.Post("/", middleware.ErrorWrapper(categoryHandler.CreateCategory))
+	read.Get("/", middleware.ErrorWrapper(categoryHandler.ListCategories))
+	write.Put("/{id}", middleware.ErrorWrapper(categoryHandler.RenameCategory))
+	write.Delete("/{id}", middleware.ErrorWrapper(categoryHandler.DeleteCategory))
+
+	return r
+}
@@ -0,0 +1,62 @@
+package category
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+)
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, userId uuid.UUID, category *CategoryDTO) (*Category, error)
+	ListCategories(ctx context.Context, userId uuid.UUID) ([]Category, error)
+	GetCategoryByID(ctx context.Context, userId, id uuid.UUID) (*Category, error)
+	RenameCategory(ctx context.Context, userId, id uuid.UUID, category *CategoryDTO) (*Category, error)
+	DeleteCategory(ctx context.Context, userId, id uuid.UUID) error
+}
+
+type categoryService struct {
+	repo CategoryRepository
+}
+
+func NewCategoryService(repo CategoryRepository) CategoryService {
+	return &categoryService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+func (s *categoryService) CreateCategory(ctx context.Context, userId uuid.UUID, category *CategoryDTO) (*Category, error) {
+	if err := validation.Struct(category); err != nil {
+		return nil, err
+	}
+
+	model := &Category{ID: uuid.New(), UserID: userId}
+	model.Rename(category.Name)
+	return s.repo.CreateCategory(ctx, model)
+}
+
+func (s *categoryService) ListCategories(ctx context.Context, userId uuid.UUID) ([]Category, error) {
+	return s.repo.ListCategories(ctx, userId)
+}
+
+func (s *categoryService) GetCategoryByID(ctx context.Context, userId, id uuid.UUID) (*Category, error) {
+	return s.repo.GetCategoryByID(ctx, userId, id)
+}
+
+func (s *categoryService) RenameCategory(ctx context.Context, userId, id uuid.UUID, category *CategoryDTO) (*Category, error) {
+	if err := validation.Struct(category); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetCategoryByID(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Rename(category.Name)
+	return s.repo.UpdateCategory(ctx, existing)
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, userId, id uuid.UUID) error {
+	return s.repo.DeleteCategory(ctx, userId, id)
+}
@@ -0,0 +1,153 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind classifies an error by how the client should react to it.
+type Kind string
+
+const (
+	KindValidation           Kind = "validation"
+	KindNotFound             Kind = "not_found"
+	KindConflict             Kind = "conflict"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindTooLarge             Kind = "too_large"
+	KindUnprocessable        Kind = "unprocessable"
+	KindUnsupported          Kind = "unsupported_media_type"
+	KindPrecondition         Kind = "precondition_failed"
+	KindPreconditionRequired Kind = "precondition_required"
+	KindInternal             Kind = "internal"
+)
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error returned by services and repositories. Code is a
+// stable machine-readable identifier; Message is safe to show to clients,
+// except for internal errors whose cause is kept in Err.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode replaces the default code of the error kind.
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// StatusCode returns the HTTP status matching the error kind.
+func (e *Error) StatusCode() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	case KindPrecondition:
+		return http.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: message, Fields: fields}
+}
+
+// InvalidField reports a single rejected field.
+func InvalidField(field, message string) *Error {
+	return Validation(message, FieldError{Field: field, Message: message})
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Code: "not_found", Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Code: "conflict", Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: "unauthorized", Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Code: "forbidden", Message: message}
+}
+
+func TooLarge(message string) *Error {
+	return &Error{Kind: KindTooLarge, Code: "request_too_large", Message: message}
+}
+
+// Unprocessable reports a well-formed request that cannot be processed as sent.
+func Unprocessable(message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: "unprocessable", Message: message}
+}
+
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: KindUnsupported, Code: "unsupported_media_type", Message: message}
+}
+
+// PreconditionFailed reports a conditional request whose precondition no
+// longer holds, e.g. a stale If-Match entity tag.
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: KindPrecondition, Code: "precondition_failed", Message: message}
+}
+
+func PreconditionRequired(message string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Code: "precondition_required", Message: message}
+}
+
+// Internal wraps an unexpected failure. Its cause is never shown to clients.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error", Err: err}
+}
+
+// From returns err as a domain error. Errors of unknown origin are internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// KindOf returns the kind of err, or KindInternal for errors of unknown origin.
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,24 @@
+package apperror
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Database translates an error returned by gorm into a domain error naming
+// the affected resource, e.g. "subscription not found".
+func Database(err error, resource string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound(resource + " not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict(resource + " already exists")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Conflict(resource + " references a missing record")
+	default:
+		return Internal(err)
+	}
+}
@@ -35,7 +35,8 @@ func ConnectDB() *gorm.DB {
 
 		var err error
 		database, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Silent),
+			Logger:         logger.Default.LogMode(logger.Silent),
+			TranslateError: true,
 		})
 		if err != nil {
 			log.Fatalf("❌ Failed to connect to the database: %v", err)
@@ -3,13 +3,50 @@ package db
 import (
 	"log"
 
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/apikey"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/idempotency"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/subscription"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
 	"gorm.io/gorm"
 )
 
 func Migrate(db *gorm.DB) {
+	if err := db.AutoMigrate(&tenant.Tenant{}); err != nil {
+		log.Fatalf("❌ Failed to migrate database: %v", err)
+	}
+	if err := backfillUserTenants(db); err != nil {
+		log.Fatalf("❌ Failed to backfill user tenants: %v", err)
+	}
+	if err := db.AutoMigrate(&user.User{}); err != nil {
+		log.Fatalf("❌ Failed to migrate database: %v", err)
+	}
+	if err := backfillUsers(db); err != nil {
+		log.Fatalf("❌ Failed to backfill users: %v", err)
+	}
+	if err := backfillSubscriptionTenants(db); err != nil {
+		log.Fatalf("❌ Failed to backfill subscription tenants: %v", err)
+	}
+	if err := backfillAPIKeyTenants(db); err != nil {
+		log.Fatalf("❌ Failed to backfill API key tenants: %v", err)
+	}
+
 	err := db.AutoMigrate(
+		&catalog.CatalogEntry{},
+		&category.Category{},
+		&tag.Tag{},
 		&subscription.Subscription{},
+		&subscription.PriceChange{},
+		&subscription.StatusChange{},
+		&subscription.Discount{},
+		&subscription.SubscriptionMember{},
+		&apikey.APIKey{},
+		&audit.AuditEntry{},
+		&idempotency.IdempotencyKey{},
 	)
 
 	if err != nil {
@@ -18,3 +55,88 @@ func Migrate(db *gorm.DB) {
 
 	log.Println("🚚 Migrations has been successfully applied")
 }
+
+// backfillUserTenants gives every existing user a personal tenant with the
+// user's ID, so the tenant column of users can be made required.
+func backfillUserTenants(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&user.User{}) || db.Migrator().HasColumn(&user.User{}, "TenantID") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO tenants (id, name, created_at, updated_at)
+			SELECT id, name, now(), now() FROM users
+			ON CONFLICT (id) DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec(`ALTER TABLE users ADD COLUMN tenant_id uuid`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE users SET tenant_id = id`).Error
+	})
+}
+
+// backfillUsers creates a user, owning a personal tenant, for every owner of
+// existing subscriptions, so the foreign key from subscriptions to users can
+// be added.
+func backfillUsers(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&subscription.Subscription{}) {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO tenants (id, name, created_at, updated_at)
+			SELECT DISTINCT user_id, '', now(), now() FROM subscriptions
+			WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = subscriptions.user_id)
+			ON CONFLICT (id) DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO users (id, name, tenant_id, role, created_at, updated_at)
+			SELECT DISTINCT user_id, '', user_id, 'owner', now(), now() FROM subscriptions
+			ON CONFLICT (id) DO NOTHING`).Error
+	})
+}
+
+// backfillSubscriptionTenants moves existing subscriptions into the tenant of
+// their owner, so the tenant column of subscriptions can be made required.
+func backfillSubscriptionTenants(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&subscription.Subscription{}) || db.Migrator().HasColumn(&subscription.Subscription{}, "TenantID") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE subscriptions ADD COLUMN tenant_id uuid`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE subscriptions SET tenant_id = users.tenant_id
+			FROM users WHERE users.id = subscriptions.user_id`).Error
+	})
+}
+
+// backfillAPIKeyTenants binds existing API keys to the only tenant when there
+// is exactly one, so the tenant column of API keys can be made required. With
+// several tenants it cannot tell which one a key acts for, so those keys are
+// removed and have to be issued again within an organization.
+func backfillAPIKeyTenants(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&apikey.APIKey{}) || db.Migrator().HasColumn(&apikey.APIKey{}, "TenantID") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE api_keys ADD COLUMN tenant_id uuid`).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(`UPDATE api_keys SET tenant_id = (SELECT id FROM tenants)
+			WHERE (SELECT count(*) FROM tenants) = 1`).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Exec(`DELETE FROM api_keys WHERE tenant_id IS NULL`)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("🔑 Removed %d API keys that could not be bound to an organization", result.RowsAffected)
+		}
+		return nil
+	})
+}
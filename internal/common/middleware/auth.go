@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+	// ScopeSystem is held by the operators of the service, who act across
+	// organizations. Only tokens carry it; it is not implied by ScopeAdmin.
+	ScopeSystem = "system"
+)
+
+type PrincipalType string
+
+const (
+	PrincipalUser   PrincipalType = "user"
+	PrincipalAPIKey PrincipalType = "api_key"
+)
+
+// Principal is the authenticated caller of a request. Users act on their own
+// data, while API key clients act on behalf of the user named in the request,
+// who must belong to TenantID, the organization the key was issued in.
+type Principal struct {
+	Type     PrincipalType
+	Subject  string
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	Scopes   []string
+}
+
+// HasScope reports whether the principal was granted scope. The admin scope
+// grants every other scope but the system scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope || (granted == ScopeAdmin && scope != ScopeSystem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies the credentials of one Authorization header scheme.
+type Authenticator interface {
+	Scheme() string
+	Authenticate(ctx context.Context, credentials string) (*Principal, error)
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequestUserID returns the id of the user the request acts for: the token
+// subject for users, or the user-id query parameter for API key clients.
+func RequestUserID(r *http.Request) (uuid.UUID, error) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return uuid.Nil, apperror.Unauthorized("authenticated user is required")
+	}
+
+	if principal.Type != PrincipalAPIKey {
+		if principal.UserID == uuid.Nil {
+			return uuid.Nil, apperror.Unauthorized("authenticated user is required")
+		}
+		return principal.UserID, nil
+	}
+
+	userIdStr := r.URL.Query().Get("user-id")
+	if userIdStr == "" {
+		return uuid.Nil, apperror.InvalidField("user-id", "user-id query parameter is required for API key clients")
+	}
+
+	userId, err := uuid.Parse(userIdStr)
+	if err != nil || userId == uuid.Nil {
+		return uuid.Nil, apperror.InvalidField("user-id", "invalid user-id format")
+	}
+	return userId, nil
+}
+
+// Authenticate rejects requests without valid credentials for one of the given
+// authenticators and stores the resolved principal in the request context.
+func Authenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, credentials, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			credentials = strings.TrimSpace(credentials)
+			if !found || credentials == "" {
+				writeUnauthorized(w, r, authenticators, "missing or malformed Authorization header")
+				return
+			}
+
+			for _, authenticator := range authenticators {
+				if !strings.EqualFold(scheme, authenticator.Scheme()) {
+					continue
+				}
+
+				principal, err := authenticator.Authenticate(r.Context(), credentials)
+				if err != nil {
+					if apperror.KindOf(err) == apperror.KindInternal {
+						writeError(w, r, err)
+						return
+					}
+					writeUnauthorized(w, r, authenticators, "invalid credentials")
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			writeUnauthorized(w, r, authenticators, "unsupported authorization scheme")
+		})
+	}
+}
+
+// RequireScope rejects requests whose principal was not granted scope.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, r, apperror.Unauthorized("authentication required"))
+				return
+			}
+			if !principal.HasScope(scope) {
+				writeError(w, r, apperror.Forbidden("missing required scope: "+scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, authenticators []Authenticator, message string) {
+	for _, authenticator := range authenticators {
+		w.Header().Add("WWW-Authenticate", authenticator.Scheme())
+	}
+	writeError(w, r, apperror.Unauthorized(message))
+}
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"encoding/json"
-	"errors"
+	"log"
 	"net/http"
 
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
-	"gorm.io/gorm"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
 )
 
 type HandlerFuncWithError func(w http.ResponseWriter, r *http.Request) error
@@ -14,36 +14,35 @@ type HandlerFuncWithError func(w http.ResponseWriter, r *http.Request) error
 func ErrorWrapper(next HandlerFuncWithError) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := next(w, r); err != nil {
-			var statusCode int
-			var response common.Response
-
-			switch {
-			case errors.Is(err, gorm.ErrRecordNotFound):
-				if r.Method == http.MethodGet {
-					statusCode = http.StatusOK
-					response = common.Response{
-						Success: true,
-						Message: "record not found",
-						Data:    struct{}{},
-					}
-				} else {
-					statusCode = http.StatusNotFound
-					response = common.Response{
-						Success: false,
-						Message: "record not found",
-					}
-				}
-			default:
-				statusCode = http.StatusBadRequest
-				response = common.Response{
-					Success: false,
-					Message: err.Error(),
-				}
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			json.NewEncoder(w).Encode(response)
+			writeError(w, r, err)
 		}
 	}
 }
+
+// writeError renders err with the status code and error code of its domain
+// error kind, as RFC 7807 problem details when the client asks for them.
+// Internal errors are logged and their details are not exposed.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperror.From(err)
+	if appErr.Kind == apperror.KindInternal {
+		log.Printf("❌ %s %s: %v", r.Method, r.URL.Path, err)
+	}
+
+	if acceptsProblem(r) {
+		writeProblem(w, r, appErr)
+		return
+	}
+
+	writeJSON(w, appErr.StatusCode(), common.Response{
+		Success: false,
+		Message: appErr.Message,
+		Code:    appErr.Code,
+		Errors:  appErr.Fields,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, response common.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
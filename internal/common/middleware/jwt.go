@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+// JWTAuthenticator verifies HS256 and RS256 bearer tokens. The token subject
+// must be the id of the user making the request.
+type JWTAuthenticator struct {
+	hmacKeys map[string][]byte
+	rsaKeys  map[string]*rsa.PublicKey
+	parser   *jwt.Parser
+}
+
+// NewJWTAuthenticatorFromEnv loads verification keys from JWT_HS256_SECRET,
+// JWT_RS256_PUBLIC_KEY_FILE (PEM) and JWT_JWKS_FILE. JWT_ISSUER and
+// JWT_AUDIENCE, when set, are required to match the token claims.
+func NewJWTAuthenticatorFromEnv() (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{
+		hmacKeys: make(map[string][]byte),
+		rsaKeys:  make(map[string]*rsa.PublicKey),
+	}
+
+	if secret := os.Getenv("JWT_HS256_SECRET"); secret != "" {
+		a.hmacKeys[""] = []byte(secret)
+	}
+
+	if path := os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RS256 public key: %w", err)
+		}
+		a.rsaKeys[""] = key
+	}
+
+	if path := os.Getenv("JWT_JWKS_FILE"); path != "" {
+		if err := a.loadJWKS(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(a.hmacKeys) == 0 && len(a.rsaKeys) == 0 {
+		return nil, errors.New("no JWT verification key configured (set JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE or JWT_JWKS_FILE)")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	a.parser = jwt.NewParser(options...)
+
+	return a, nil
+}
+
+func (a *JWTAuthenticator) Scheme() string {
+	return "Bearer"
+}
+
+// tokenClaims adds the space separated OAuth scope claim to the registered
+// claims. Tokens without it get read and write access to their user's data.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credentials string) (*Principal, error) {
+	var claims tokenClaims
+	if _, err := a.parser.ParseWithClaims(credentials, &claims, a.key); err != nil {
+		return nil, apperror.Unauthorized(err.Error())
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, apperror.Unauthorized("token subject is not a valid user id")
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if len(scopes) == 0 {
+		scopes = []string{ScopeRead, ScopeWrite}
+	}
+
+	return &Principal{
+		Type:    PrincipalUser,
+		Subject: claims.Subject,
+		UserID:  userId,
+		Scopes:  scopes,
+	}, nil
+}
+
+// key picks the verification key by the token algorithm and key id. Tokens
+// without a kid fall back to the key configured without one.
+func (a *JWTAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if key, ok := a.hmacKeys[kid]; ok {
+			return key, nil
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if key, ok := a.rsaKeys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s key with id %q", token.Method.Alg(), kid)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// loadJWKS reads RSA and symmetric ("oct") keys from a local JWKS file.
+func (a *JWTAuthenticator) loadJWKS(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return fmt.Errorf("invalid JWKS file: %w", err)
+	}
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err := key.rsaPublicKey()
+			if err != nil {
+				return fmt.Errorf("invalid JWKS key %q: %w", key.Kid, err)
+			}
+			a.rsaKeys[key.Kid] = publicKey
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil {
+				return fmt.Errorf("invalid JWKS key %q: %w", key.Kid, err)
+			}
+			a.hmacKeys[key.Kid] = secret
+		}
+	}
+	return nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+const problemContentType = "application/problem+json"
+
+// problem is an RFC 7807 problem details document. Code and Errors are
+// extension members carrying the domain error code and field violations.
+type problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code,omitempty"`
+	Errors   []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// acceptsProblem reports whether the client explicitly asked for problem
+// details in its Accept header.
+func acceptsProblem(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || mediaType != problemContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, appErr *apperror.Error) {
+	status := appErr.StatusCode()
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appErr.Message,
+		Instance: r.URL.RequestURI(),
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+)
+
+// TenantResolver returns the tenant of a user and the user's role there.
+type TenantResolver func(ctx context.Context, userId uuid.UUID) (tenancy.Membership, error)
+
+// ResolveTenant stores the tenant and role of the user a request acts for in
+// the request context, so everything below it is scoped to that tenant. API
+// key clients may only act for users of the tenant their key belongs to.
+func ResolveTenant(resolve TenantResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, err := RequestUserID(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			membership, err := resolve(r.Context(), userId)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Type == PrincipalAPIKey && principal.TenantID != membership.TenantID {
+				writeError(w, r, apperror.Forbidden("user-id belongs to a different organization"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenancy.WithMembership(r.Context(), membership)))
+		})
+	}
+}
+
+// RequirePermission rejects requests whose user's role in their tenant is
+// not granted permission by policy. It must run after ResolveTenant.
+func RequirePermission(policy *rbac.Policy, permission rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			membership, err := RequestTenant(r)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			if !policy.Allows(membership.Role, permission) {
+				writeError(w, r, apperror.Forbidden("missing required permission: "+string(permission)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestTenant returns the tenant and role ResolveTenant stored for the
+// request.
+func RequestTenant(r *http.Request) (tenancy.Membership, error) {
+	membership, ok := tenancy.FromContext(r.Context())
+	if !ok {
+		return tenancy.Membership{}, apperror.Forbidden("organization membership required")
+	}
+	return membership, nil
+}
@@ -0,0 +1,334 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+type operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+	// hasValue distinguishes an explicit null value from a missing one.
+	hasValue bool
+}
+
+// applyJSONPatch implements RFC 6902. Operations are applied in order and the
+// whole patch fails if any of them does.
+func applyJSONPatch(doc, patch interface{}) (interface{}, error) {
+	operations, err := parseOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range operations {
+		doc, err = op.apply(doc)
+		if err != nil {
+			var appErr *apperror.Error
+			if e, ok := err.(*apperror.Error); ok {
+				appErr = e
+			} else {
+				appErr = apperror.Validation(err.Error())
+			}
+			appErr.Message = fmt.Sprintf("patch operation %d (%s %s): %s", i, op.Op, op.Path, appErr.Message)
+			return nil, appErr
+		}
+	}
+	return doc, nil
+}
+
+func parseOperations(patch interface{}) ([]operation, error) {
+	items, ok := patch.([]interface{})
+	if !ok {
+		return nil, apperror.Validation("JSON Patch document must be an array of operations")
+	}
+
+	operations := make([]operation, 0, len(items))
+	for i, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, apperror.Validation(fmt.Sprintf("patch operation %d must be an object", i))
+		}
+
+		var op operation
+		op.Op, _ = object["op"].(string)
+		op.Path, ok = object["path"].(string)
+		if !ok {
+			return nil, apperror.Validation(fmt.Sprintf("patch operation %d is missing path", i))
+		}
+		op.From, _ = object["from"].(string)
+		op.Value, op.hasValue = object["value"]
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if !op.hasValue {
+				return nil, apperror.Validation(fmt.Sprintf("patch operation %d (%s) is missing value", i, op.Op))
+			}
+		case "move", "copy":
+			if _, ok := object["from"].(string); !ok {
+				return nil, apperror.Validation(fmt.Sprintf("patch operation %d (%s) is missing from", i, op.Op))
+			}
+		case "remove":
+		default:
+			return nil, apperror.Validation(fmt.Sprintf("patch operation %d has unknown op %q", i, op.Op))
+		}
+		operations = append(operations, op)
+	}
+	return operations, nil
+}
+
+func (op operation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, op.Value)
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "replace":
+		if doc, _, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, op.Value)
+	case "test":
+		value, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(normalize(value), normalize(op.Value)) {
+			return nil, apperror.Conflict("test failed")
+		}
+		return doc, nil
+	}
+
+	from, err := parsePointer(op.From)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "move":
+		if isPrefix(from, path) && len(from) != len(path) {
+			return nil, apperror.Validation("cannot move a value into one of its children")
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default: // copy
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(value))
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, apperror.Validation(fmt.Sprintf("invalid JSON pointer %q", pointer))
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			child, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path member %q does not exist", token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("path member %q does not exist", token)
+		}
+	}
+	return node, nil
+}
+
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token, rest := path[0], path[1:]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("path member %q does not exist", token)
+		}
+		updated, err := add(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+		return container, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			index := len(container)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := add(container[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = updated
+		return container, nil
+	default:
+		return nil, fmt.Errorf("path member %q does not exist", token)
+	}
+}
+
+// remove deletes the value at path and returns the updated node together with
+// the removed value.
+func remove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	token, rest := path[0], path[1:]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path member %q does not exist", token)
+		}
+		if len(rest) == 0 {
+			delete(container, token)
+			return container, child, nil
+		}
+		updated, removed, err := remove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = updated
+		return container, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := container[index]
+			return append(container[:index], container[index+1:]...), removed, nil
+		}
+		updated, removed, err := remove(container[index], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = updated
+		return container, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("path member %q does not exist", token)
+	}
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for name, child := range v {
+			copied[name] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// normalize makes numbers comparable regardless of their textual form, so
+// that 10 and 10.0 test as equal.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for name, child := range v {
+			normalized[name] = normalize(child)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, child := range v {
+			normalized[i] = normalize(child)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
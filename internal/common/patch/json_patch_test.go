@@ -0,0 +1,109 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/price","value":799}]`,
+			want:  `{"name":"Netflix","price":799,"tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "append with -",
+			patch: `[{"op":"add","path":"/tags/-","value":"kids"}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["video","family","kids"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "insert before an index",
+			patch: `[{"op":"add","path":"/tags/0","value":"kids"}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["kids","video","family"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "remove an array element",
+			patch: `[{"op":"remove","path":"/tags/1"}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["video"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "move out of a child",
+			patch: `[{"op":"move","from":"/owner/address","path":"/address"}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["video","family"],"owner":{"id":"a"},"address":{"city":"Moscow"}}`,
+		},
+		{
+			name:  "move onto itself",
+			patch: `[{"op":"move","from":"/owner","path":"/owner"}]`,
+			want:  document,
+		},
+		{
+			name:  "copy is independent of its source",
+			patch: `[{"op":"copy","from":"/owner","path":"/payer"},{"op":"replace","path":"/payer/id","value":"b"}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}},"payer":{"id":"b","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "test compares numbers by value",
+			patch: `[{"op":"test","path":"/price","value":599.0},{"op":"remove","path":"/price"}]`,
+			want:  `{"name":"Netflix","tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "escaped pointer tokens",
+			patch: `[{"op":"add","path":"/a~1b~0c","value":true}]`,
+			want:  `{"name":"Netflix","price":599,"tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}},"a/b~c":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(JSONPatch, []byte(document), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  apperror.Kind
+	}{
+		{"failed test", `[{"op":"test","path":"/price","value":600}]`, apperror.KindConflict},
+		{"test of a removed member", `[{"op":"remove","path":"/name"},{"op":"test","path":"/name","value":"Netflix"}]`, apperror.KindValidation},
+		{"move into its own child", `[{"op":"move","from":"/owner","path":"/owner/address/owner"}]`, apperror.KindValidation},
+		{"replace a missing member", `[{"op":"replace","path":"/currency","value":"RUB"}]`, apperror.KindValidation},
+		{"remove a missing member", `[{"op":"remove","path":"/currency"}]`, apperror.KindValidation},
+		{"add under a missing parent", `[{"op":"add","path":"/plan/name","value":"family"}]`, apperror.KindValidation},
+		{"leading zero index", `[{"op":"replace","path":"/tags/01","value":"kids"}]`, apperror.KindValidation},
+		{"index past the end", `[{"op":"add","path":"/tags/3","value":"kids"}]`, apperror.KindValidation},
+		{"negative index", `[{"op":"remove","path":"/tags/-1"}]`, apperror.KindValidation},
+		{"- outside add", `[{"op":"replace","path":"/tags/-","value":"kids"}]`, apperror.KindValidation},
+		{"pointer without a leading slash", `[{"op":"remove","path":"price"}]`, apperror.KindValidation},
+		{"remove the whole document", `[{"op":"remove","path":""}]`, apperror.KindValidation},
+		{"unknown op", `[{"op":"merge","path":"/price","value":1}]`, apperror.KindValidation},
+		{"missing value", `[{"op":"add","path":"/price"}]`, apperror.KindValidation},
+		{"missing from", `[{"op":"copy","path":"/price"}]`, apperror.KindValidation},
+		{"not an array", `{"op":"remove","path":"/price"}`, apperror.KindValidation},
+		{"malformed JSON", `[{"op":`, apperror.KindValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply(JSONPatch, []byte(document), []byte(tt.patch))
+			if err == nil {
+				t.Fatal("Apply succeeded, want an error")
+			}
+			if kind := apperror.KindOf(err); kind != tt.want {
+				t.Errorf("error kind = %s, want %s (%v)", kind, tt.want, err)
+			}
+		})
+	}
+}
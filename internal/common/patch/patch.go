@@ -0,0 +1,94 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+// Format is the kind of patch document a PATCH request carries.
+type Format string
+
+const (
+	MergePatch Format = "application/merge-patch+json"
+	JSONPatch  Format = "application/json-patch+json"
+)
+
+// FormatFromContentType returns the patch format of a Content-Type header.
+func FormatFromContentType(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		switch Format(mediaType) {
+		case MergePatch, JSONPatch:
+			return Format(mediaType), nil
+		}
+	}
+	return "", apperror.UnsupportedMediaType("PATCH requires Content-Type " + string(MergePatch) + " or " + string(JSONPatch))
+}
+
+// Apply applies a patch document of the given format to the JSON document doc
+// and returns the patched document.
+func Apply(format Format, doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	patchValue, err := decode(patch)
+	if err != nil {
+		return nil, apperror.Validation("patch document contains malformed JSON")
+	}
+
+	var patched interface{}
+	switch format {
+	case MergePatch:
+		patched = mergePatch(target, patchValue)
+	case JSONPatch:
+		patched, err = applyJSONPatch(target, patchValue)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, apperror.UnsupportedMediaType("unsupported patch format " + string(format))
+	}
+
+	return json.Marshal(patched)
+}
+
+// decode keeps numbers as json.Number so patching does not lose precision.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// mergePatch implements RFC 7386: object members of the patch replace those of
+// the target, null members remove them, and any other value replaces the
+// target as a whole.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
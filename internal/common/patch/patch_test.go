@@ -0,0 +1,90 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+const document = `{"name":"Netflix","price":599,"tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}}}`
+
+// assertJSON compares two JSON documents ignoring key order and whitespace.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result is not JSON: %s", got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("want is not JSON: %s", want)
+	}
+	gotJSON, _ := json.Marshal(gotValue)
+	wantJSON, _ := json.Marshal(wantValue)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("patched document = %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "replaces and adds members",
+			patch: `{"price":799,"currency":"RUB"}`,
+			want:  `{"name":"Netflix","price":799,"currency":"RUB","tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "null removes a member",
+			patch: `{"tags":null,"owner":{"address":null}}`,
+			want:  `{"name":"Netflix","price":599,"owner":{"id":"a"}}`,
+		},
+		{
+			name:  "arrays are replaced as a whole",
+			patch: `{"tags":["music"]}`,
+			want:  `{"name":"Netflix","price":599,"tags":["music"],"owner":{"id":"a","address":{"city":"Moscow"}}}`,
+		},
+		{
+			name:  "nested objects are merged",
+			patch: `{"owner":{"address":{"zip":"101000"}}}`,
+			want:  `{"name":"Netflix","price":599,"tags":["video","family"],"owner":{"id":"a","address":{"city":"Moscow","zip":"101000"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(MergePatch, []byte(document), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Format
+		wantErr     bool
+	}{
+		{"application/merge-patch+json", MergePatch, false},
+		{"application/json-patch+json; charset=utf-8", JSONPatch, false},
+		{"application/json", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatFromContentType(tt.contentType)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FormatFromContentType(%q) = %q, %v, want %q (error %v)", tt.contentType, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && apperror.KindOf(err) != apperror.KindUnsupported {
+			t.Errorf("FormatFromContentType(%q) error kind = %s, want %s", tt.contentType, apperror.KindOf(err), apperror.KindUnsupported)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+)
+
+// Permission names an action on a resource as "resource:action". In grants,
+// "resource:*" stands for every action on the resource and "*" for every
+// permission.
+type Permission string
+
+const (
+	SubscriptionRead  Permission = "subscription:read"
+	SubscriptionWrite Permission = "subscription:write"
+	ReportRead        Permission = "report:read"
+	CategoryRead      Permission = "category:read"
+	CategoryWrite     Permission = "category:write"
+	TagRead           Permission = "tag:read"
+	TagWrite          Permission = "tag:write"
+	CatalogRead       Permission = "catalog:read"
+	// UserRead and UserWrite cover the user's own profile and settings.
+	UserRead  Permission = "user:read"
+	UserWrite Permission = "user:write"
+	// AdminReports covers reports on the whole organization.
+	AdminReports Permission = "admin:reports"
+	// AdminMembers covers changing the roles of organization members.
+	AdminMembers Permission = "admin:members"
+	// AdminUsers covers managing the users of the organization.
+	AdminUsers Permission = "admin:users"
+	// AdminAPIKeys covers managing the API keys of the organization.
+	AdminAPIKeys Permission = "admin:apikeys"
+	// AdminAudit covers querying the audit log of the organization.
+	AdminAudit Permission = "admin:audit"
+	// MemberRead covers listing the members of the organization.
+	MemberRead Permission = "member:read"
+	// OrganizationRead and OrganizationWrite cover the organization itself.
+	OrganizationRead  Permission = "organization:read"
+	OrganizationWrite Permission = "organization:write"
+)
+
+const wildcard = "*"
+
+// Policy grants permissions to the roles users have in their tenant. Roles
+// it does not mention are granted nothing.
+type Policy struct {
+	grants map[tenancy.Role][]Permission
+}
+
+// DefaultPolicy is the policy used without a policy file: owners may do
+// anything, admins everything but changing the organization, members manage
+// their own subscriptions, categories, tags and settings and viewers only
+// read them. Every role may see its organization and its members.
+func DefaultPolicy() *Policy {
+	return &Policy{grants: map[tenancy.Role][]Permission{
+		tenancy.RoleOwner: {wildcard},
+		tenancy.RoleAdmin: {
+			"subscription:*", "report:*", "category:*", "tag:*", "catalog:*", "user:*", "admin:*",
+			MemberRead, OrganizationRead,
+		},
+		tenancy.RoleMember: {
+			SubscriptionRead, SubscriptionWrite, ReportRead,
+			CategoryRead, CategoryWrite, TagRead, TagWrite, CatalogRead, UserRead, UserWrite,
+			MemberRead, OrganizationRead,
+		},
+		tenancy.RoleViewer: {
+			SubscriptionRead, ReportRead, CategoryRead, TagRead, CatalogRead, UserRead,
+			MemberRead, OrganizationRead,
+		},
+	}}
+}
+
+// NewPolicy builds a policy from the permissions granted to each role,
+// rejecting grants that are not "*", "resource:*" or "resource:action".
+func NewPolicy(grants map[tenancy.Role][]Permission) (*Policy, error) {
+	policy := &Policy{grants: make(map[tenancy.Role][]Permission, len(grants))}
+	for role, permissions := range grants {
+		if strings.TrimSpace(string(role)) == "" {
+			return nil, fmt.Errorf("role name cannot be empty")
+		}
+		for _, permission := range permissions {
+			if err := validateGrant(permission); err != nil {
+				return nil, fmt.Errorf("role %s: %w", role, err)
+			}
+		}
+		policy.grants[role] = append([]Permission(nil), permissions...)
+	}
+	return policy, nil
+}
+
+type policyFile struct {
+	Roles map[tenancy.Role][]Permission `json:"roles"`
+}
+
+// LoadPolicyFile reads a JSON file of the permissions granted to each role:
+//
+//	{"roles": {"owner": ["*"], "member": ["subscription:*", "report:read"], "viewer": ["subscription:read"]}}
+func LoadPolicyFile(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file policyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy file: %w", err)
+	}
+	if len(file.Roles) == 0 {
+		return nil, fmt.Errorf("policy file grants no roles")
+	}
+	return NewPolicy(file.Roles)
+}
+
+// Allows reports whether role is granted permission.
+func (p *Policy) Allows(role tenancy.Role, permission Permission) bool {
+	for _, grant := range p.grants[role] {
+		if matches(grant, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------- helpers --------------------------
+
+func matches(grant, permission Permission) bool {
+	if grant == wildcard || grant == permission {
+		return true
+	}
+	resource, action, ok := strings.Cut(string(grant), ":")
+	if !ok || action != wildcard {
+		return false
+	}
+	return strings.HasPrefix(string(permission), resource+":")
+}
+
+func validateGrant(permission Permission) error {
+	if permission == wildcard {
+		return nil
+	}
+	resource, action, ok := strings.Cut(string(permission), ":")
+	if !ok || resource == "" || action == "" || resource == wildcard || strings.Contains(action, ":") {
+		return fmt.Errorf("invalid permission %q (expected resource:action, resource:* or *)", permission)
+	}
+	if strings.Contains(action, wildcard) && action != wildcard {
+		return fmt.Errorf("invalid permission %q (wildcards must replace the whole action)", permission)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package rbac_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/app"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/apikey"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/subscription"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := rbac.DefaultPolicy()
+
+	tests := []struct {
+		role       tenancy.Role
+		permission rbac.Permission
+		want       bool
+	}{
+		{tenancy.RoleOwner, rbac.SubscriptionRead, true},
+		{tenancy.RoleOwner, rbac.SubscriptionWrite, true},
+		{tenancy.RoleOwner, rbac.ReportRead, true},
+		{tenancy.RoleOwner, rbac.AdminReports, true},
+		{tenancy.RoleOwner, rbac.AdminMembers, true},
+		{tenancy.RoleOwner, rbac.OrganizationWrite, true},
+
+		{tenancy.RoleAdmin, rbac.SubscriptionRead, true},
+		{tenancy.RoleAdmin, rbac.SubscriptionWrite, true},
+		{tenancy.RoleAdmin, rbac.ReportRead, true},
+		{tenancy.RoleAdmin, rbac.AdminReports, true},
+		{tenancy.RoleAdmin, rbac.AdminMembers, true},
+		{tenancy.RoleAdmin, rbac.OrganizationWrite, false},
+
+		{tenancy.RoleMember, rbac.SubscriptionRead, true},
+		{tenancy.RoleMember, rbac.SubscriptionWrite, true},
+		{tenancy.RoleMember, rbac.ReportRead, true},
+		{tenancy.RoleMember, rbac.AdminReports, false},
+		{tenancy.RoleMember, rbac.AdminMembers, false},
+		{tenancy.RoleMember, rbac.OrganizationWrite, false},
+
+		{tenancy.RoleViewer, rbac.SubscriptionRead, true},
+		{tenancy.RoleViewer, rbac.SubscriptionWrite, false},
+		{tenancy.RoleViewer, rbac.ReportRead, true},
+		{tenancy.RoleViewer, rbac.AdminReports, false},
+		{tenancy.RoleViewer, rbac.AdminMembers, false},
+		{tenancy.RoleViewer, rbac.OrganizationWrite, false},
+
+		{"", rbac.SubscriptionRead, false},
+		{"guest", rbac.SubscriptionRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Allows(tt.role, tt.permission); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultPolicyResourceRoutes(t *testing.T) {
+	policy := rbac.DefaultPolicy()
+
+	reads := []rbac.Permission{rbac.CategoryRead, rbac.TagRead, rbac.CatalogRead, rbac.UserRead, rbac.MemberRead, rbac.OrganizationRead}
+	writes := []rbac.Permission{rbac.CategoryWrite, rbac.TagWrite, rbac.UserWrite}
+	admin := []rbac.Permission{rbac.AdminUsers, rbac.AdminAPIKeys, rbac.AdminAudit}
+
+	tests := []struct {
+		role        tenancy.Role
+		permissions []rbac.Permission
+		want        bool
+	}{
+		{tenancy.RoleOwner, reads, true},
+		{tenancy.RoleOwner, writes, true},
+		{tenancy.RoleOwner, admin, true},
+
+		{tenancy.RoleAdmin, reads, true},
+		{tenancy.RoleAdmin, writes, true},
+		{tenancy.RoleAdmin, admin, true},
+
+		{tenancy.RoleMember, reads, true},
+		{tenancy.RoleMember, writes, true},
+		{tenancy.RoleMember, admin, false},
+
+		{tenancy.RoleViewer, reads, true},
+		{tenancy.RoleViewer, writes, false},
+		{tenancy.RoleViewer, admin, false},
+	}
+
+	for _, tt := range tests {
+		for _, permission := range tt.permissions {
+			if got := policy.Allows(tt.role, permission); got != tt.want {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, permission, got, tt.want)
+			}
+		}
+	}
+}
+
+// systemRoutes act across organizations, so they require the system scope
+// instead of a permission.
+var systemRoutes = map[string]bool{
+	"POST /api/catalog/":                  true,
+	"PUT /api/catalog/{id}":               true,
+	"DELETE /api/catalog/{id}":            true,
+	"POST /api/organizations/":            true,
+	"POST /api/organizations/{id}/users/": true,
+}
+
+// TestRoutesRequirePermission walks every route of the API with a role the
+// policy grants nothing and expects each to be rejected before reaching its
+// handler.
+func TestRoutesRequirePermission(t *testing.T) {
+	router := newTestRouter("guest")
+
+	routes := 0
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/api/") {
+			return nil
+		}
+		routes++
+
+		want := "missing required permission"
+		if systemRoutes[method+" "+route] {
+			want = "missing required scope: system"
+		}
+		assertForbidden(t, router, method, route, want)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	if routes == 0 {
+		t.Fatal("no API routes found")
+	}
+}
+
+// TestViewerCannotWrite expects every route that changes data to be rejected
+// for viewers.
+func TestViewerCannotWrite(t *testing.T) {
+	router := newTestRouter(tenancy.RoleViewer)
+
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/api/") || method == http.MethodGet || systemRoutes[method+" "+route] {
+			return nil
+		}
+		assertForbidden(t, router, method, route, "missing required permission")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+}
+
+func TestPolicyWildcards(t *testing.T) {
+	policy, err := rbac.NewPolicy(map[tenancy.Role][]rbac.Permission{
+		"auditor": {"report:*"},
+		"root":    {"*"},
+		"editor":  {"subscription:write"},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	tests := []struct {
+		role       tenancy.Role
+		permission rbac.Permission
+		want       bool
+	}{
+		{"auditor", "report:read", true},
+		{"auditor", "report:export", true},
+		{"auditor", "reports:read", false},
+		{"auditor", "report", false},
+		{"auditor", "subscription:read", false},
+		{"root", "anything:at-all", true},
+		{"editor", "subscription:write", true},
+		{"editor", "subscription:read", false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Allows(tt.role, tt.permission); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicyRejectsInvalidGrants(t *testing.T) {
+	invalid := []rbac.Permission{"", "subscription", ":read", "subscription:", "*:read", "subscription:re*", "a:b:c"}
+
+	for _, permission := range invalid {
+		if _, err := rbac.NewPolicy(map[tenancy.Role][]rbac.Permission{"member": {permission}}); err == nil {
+			t.Errorf("NewPolicy accepted invalid permission %q", permission)
+		}
+	}
+
+	if _, err := rbac.NewPolicy(map[tenancy.Role][]rbac.Permission{" ": {"*"}}); err == nil {
+		t.Error("NewPolicy accepted an empty role name")
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	path := writePolicyFile(t, `{"roles": {"owner": ["*"], "member": ["subscription:read"]}}`)
+
+	policy, err := rbac.LoadPolicyFile(path)
+	if err != nil {
+		t.Fatalf("LoadPolicyFile: %v", err)
+	}
+
+	if !policy.Allows(tenancy.RoleOwner, rbac.OrganizationWrite) {
+		t.Error("owner should be allowed organization:write")
+	}
+	if !policy.Allows(tenancy.RoleMember, rbac.SubscriptionRead) {
+		t.Error("member should be allowed subscription:read")
+	}
+	if policy.Allows(tenancy.RoleMember, rbac.SubscriptionWrite) {
+		t.Error("member should not be allowed subscription:write")
+	}
+	if policy.Allows(tenancy.RoleViewer, rbac.SubscriptionRead) {
+		t.Error("roles missing from the file should be allowed nothing")
+	}
+}
+
+func TestLoadPolicyFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"malformed", `{"roles":`, "invalid policy file"},
+		{"no roles", `{"roles": {}}`, "grants no roles"},
+		{"invalid grant", `{"roles": {"member": ["subscription"]}}`, `invalid permission "subscription"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rbac.LoadPolicyFile(writePolicyFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPolicyFile error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := rbac.LoadPolicyFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadPolicyFile accepted a missing file")
+	}
+}
+
+func writePolicyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var routeParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// newTestRouter builds the API router with handlers that have no services:
+// requests authenticate as a user with the read, write and admin scopes and
+// resolve to role in a new organization, so any request that gets past the
+// permission checks panics and is answered with 500.
+func newTestRouter(role tenancy.Role) chi.Router {
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := &middleware.Principal{
+				Type:   middleware.PrincipalUser,
+				UserID: uuid.New(),
+				Scopes: []string{middleware.ScopeRead, middleware.ScopeWrite, middleware.ScopeAdmin},
+			}
+			next.ServeHTTP(w, r.WithContext(middleware.WithPrincipal(r.Context(), principal)))
+		})
+	}
+	resolveTenant := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			membership := tenancy.Membership{TenantID: uuid.New(), Role: role}
+			next.ServeHTTP(w, r.WithContext(tenancy.WithMembership(r.Context(), membership)))
+		})
+	}
+	passthrough := func(next http.Handler) http.Handler { return next }
+
+	return app.NewRouter(
+		&subscription.SubscriptionHandler{},
+		&apikey.APIKeyHandler{},
+		&audit.AuditHandler{},
+		&catalog.CatalogHandler{},
+		&category.CategoryHandler{},
+		&tag.TagHandler{},
+		&user.UserHandler{},
+		&tenant.TenantHandler{},
+		authenticate,
+		resolveTenant,
+		rbac.DefaultPolicy(),
+		passthrough,
+	)
+}
+
+func assertForbidden(t *testing.T, router http.Handler, method, route, want string) {
+	t.Helper()
+	path := routeParam.ReplaceAllString(route, uuid.NewString())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("%s %s = %d %s, want 403 with %q", method, route, recorder.Code, strings.TrimSpace(recorder.Body.String()), want)
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+// MaxBodyBytes limits the size of JSON request bodies.
+const MaxBodyBytes = 1 << 20
+
+// DecodeJSON strictly decodes a single JSON value from the request body into
+// dst: bodies over MaxBodyBytes, unknown fields and trailing data are rejected.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeStrict(http.MaxBytesReader(w, r.Body, MaxBodyBytes), dst)
+}
+
+// UnmarshalJSON decodes data into dst as strictly as DecodeJSON.
+func UnmarshalJSON(data []byte, dst interface{}) error {
+	return decodeStrict(bytes.NewReader(data), dst)
+}
+
+// ReadBody reads the whole request body, rejecting bodies over MaxBodyBytes.
+func ReadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, apperror.Validation("request body is required")
+	}
+	return body, nil
+}
+
+func decodeStrict(reader io.Reader, dst interface{}) error {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return apperror.Validation("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperror.Validation("request body is required")
+	case errors.As(err, &maxBytesError):
+		return apperror.TooLarge(fmt.Sprintf("request body must not exceed %d bytes", maxBytesError.Limit))
+	case errors.As(err, &syntaxError), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.Validation("request body contains malformed JSON")
+	case errors.As(err, &typeError):
+		return apperror.InvalidField(typeError.Field, fmt.Sprintf("%s must be of type %s", typeError.Field, typeError.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperror.InvalidField(field, fmt.Sprintf("unknown field %s", field))
+	default:
+		return apperror.Validation("invalid request body: " + err.Error())
+	}
+}
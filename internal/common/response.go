@@ -1,7 +1,11 @@
 package common
 
+import "github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Success bool                  `json:"success"`
+	Message string                `json:"message,omitempty"`
+	Code    string                `json:"code,omitempty"`
+	Errors  []apperror.FieldError `json:"errors,omitempty"`
+	Data    interface{}           `json:"data,omitempty"`
 }
@@ -0,0 +1,70 @@
+package tenancy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const (
+	membershipKey contextKey = "membership"
+	unscopedKey   contextKey = "unscoped"
+)
+
+// Role is what a user may do within their tenant.
+type Role string
+
+const (
+	// RoleOwner manages the tenant itself, including who its owners are.
+	RoleOwner Role = "owner"
+	// RoleAdmin manages the members of the tenant and reads its totals.
+	RoleAdmin Role = "admin"
+	// RoleMember manages their own subscriptions.
+	RoleMember Role = "member"
+	// RoleViewer only reads their own subscriptions.
+	RoleViewer Role = "viewer"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// AtLeast reports whether the role grants everything other grants. Unknown
+// roles grant nothing.
+func (r Role) AtLeast(other Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[other]
+}
+
+// Membership is the tenant a request acts in and the role of the acting user
+// there.
+type Membership struct {
+	TenantID uuid.UUID
+	Role     Role
+}
+
+func WithMembership(ctx context.Context, membership Membership) context.Context {
+	return context.WithValue(ctx, membershipKey, membership)
+}
+
+func FromContext(ctx context.Context) (Membership, bool) {
+	membership, ok := ctx.Value(membershipKey).(Membership)
+	return membership, ok
+}
+
+// WithoutTenant marks ctx as belonging to a system task that works across
+// every tenant, such as the trash retention job.
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey, true)
+}
+
+// IsUnscoped reports whether ctx was marked by WithoutTenant.
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey).(bool)
+	return unscoped
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+var (
+	validate = newValidator()
+
+	messagesMu sync.RWMutex
+	// messages describe a failed rule; %s is replaced with the rule parameter.
+	messages = map[string]string{
+		"required":         "is required",
+		"required_without": "is required",
+		"notblank":         "must not be blank",
+		"oneof":            "must be one of: %s",
+		"uuid":             "must be a valid UUID",
+		"url":              "must be a valid URL",
+		"email":            "must be a valid email address",
+		"timezone":         "must be a valid IANA timezone",
+	}
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON names, as clients know them.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	return v
+}
+
+// Register adds a field rule usable in validate tags. message describes a
+// failure and may reference the rule parameter with %s.
+func Register(tag string, fn validator.Func, message string) {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+	RegisterMessage(tag, message)
+}
+
+// RegisterStruct adds a cross-field rule for the given struct types. It reports
+// failures with StructLevel.ReportError using tags registered via RegisterMessage.
+func RegisterStruct(fn validator.StructLevelFunc, types ...interface{}) {
+	validate.RegisterStructValidation(fn, types...)
+}
+
+func RegisterMessage(tag, message string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[tag] = message
+}
+
+// Struct checks the validate tags of s and returns every violation at once as
+// a validation error.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apperror.Internal(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldPath(fieldError),
+			Message: fieldPath(fieldError) + " " + message(fieldError),
+		})
+	}
+	return apperror.Validation(fields[0].Message, fields...)
+}
+
+// fieldPath drops the struct name from the namespace, e.g.
+// "SubscriptionCreateDTO.scopes[0]" becomes "scopes[0]".
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return path
+}
+
+func message(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "min", "max", "len":
+		return lengthMessage(fieldError)
+	}
+
+	messagesMu.RLock()
+	format, ok := messages[fieldError.Tag()]
+	messagesMu.RUnlock()
+	if !ok {
+		return "is invalid"
+	}
+	if strings.Contains(format, "%s") {
+		return fmt.Sprintf(format, fieldError.Param())
+	}
+	return format
+}
+
+func lengthMessage(fieldError validator.FieldError) string {
+	var bound string
+	switch fieldError.Tag() {
+	case "min":
+		bound = "at least"
+	case "max":
+		bound = "at most"
+	default:
+		bound = "exactly"
+	}
+
+	switch fieldError.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters long", bound, fieldError.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must contain %s %s items", bound, fieldError.Param())
+	default:
+		return fmt.Sprintf("must be %s %s", bound, fieldError.Param())
+	}
+}
@@ -0,0 +1,136 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// RateProvider returns how many units of to one unit of from was worth at the
+// given moment.
+type RateProvider interface {
+	Rate(from, to string, at time.Time) (float64, error)
+}
+
+// Convert converts amount between currencies at the rate effective at the
+// given moment, rounding to the nearest whole unit.
+func Convert(provider RateProvider, amount int, from, to string, at time.Time) (int, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	rate, err := provider.Rate(from, to, at)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(float64(amount) * rate)), nil
+}
+
+// RateSnapshot holds the rates of every currency against the base currency
+// from Date until the next snapshot.
+type RateSnapshot struct {
+	Date  time.Time          `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// StaticRateProvider serves dated rates kept in memory, so past months convert
+// at the rates that were effective back then.
+type StaticRateProvider struct {
+	base      string
+	snapshots []RateSnapshot
+}
+
+// NewStaticRateProvider creates a provider for rates quoted against base.
+// Without snapshots it can only convert a currency into itself.
+func NewStaticRateProvider(base string, snapshots ...RateSnapshot) *StaticRateProvider {
+	sorted := make([]RateSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	return &StaticRateProvider{base: Normalize(base), snapshots: sorted}
+}
+
+type rateFile struct {
+	Base      string `json:"base"`
+	Snapshots []struct {
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	} `json:"snapshots"`
+}
+
+// LoadRateFile reads a JSON file of dated rates:
+//
+//	{"base": "USD", "snapshots": [{"date": "2024-01-01", "rates": {"EUR": 0.91, "RUB": 89.5}}]}
+func LoadRateFile(path string) (*StaticRateProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate file: %w", err)
+	}
+	if err := Validate(Normalize(file.Base)); err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]RateSnapshot, 0, len(file.Snapshots))
+	for _, s := range file.Snapshots {
+		date, err := time.Parse(time.DateOnly, s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate date %q: %w", s.Date, err)
+		}
+
+		rates := make(map[string]float64, len(s.Rates))
+		for code, rate := range s.Rates {
+			code = Normalize(code)
+			if err := Validate(code); err != nil {
+				return nil, err
+			}
+			if rate <= 0 {
+				return nil, fmt.Errorf("exchange rate for %s on %s must be positive", code, s.Date)
+			}
+			rates[code] = rate
+		}
+		snapshots = append(snapshots, RateSnapshot{Date: date, Rates: rates})
+	}
+
+	return NewStaticRateProvider(file.Base, snapshots...), nil
+}
+
+func (p *StaticRateProvider) Rate(from, to string, at time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	i := sort.Search(len(p.snapshots), func(i int) bool {
+		return p.snapshots[i].Date.After(at)
+	})
+	if i == 0 {
+		return 0, fmt.Errorf("no exchange rate from %s to %s on %s", from, to, at.Format(time.DateOnly))
+	}
+	snapshot := p.snapshots[i-1]
+
+	fromRate, ok := p.baseRate(snapshot, from)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s on %s", from, at.Format(time.DateOnly))
+	}
+	toRate, ok := p.baseRate(snapshot, to)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s on %s", to, at.Format(time.DateOnly))
+	}
+	return toRate / fromRate, nil
+}
+
+func (p *StaticRateProvider) baseRate(snapshot RateSnapshot, code string) (float64, bool) {
+	if code == p.base {
+		return 1, true
+	}
+	rate, ok := snapshot.Rates[code]
+	return rate, ok
+}
@@ -0,0 +1,175 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+const (
+	HeaderName = "Idempotency-Key"
+	// ReplayedHeader marks responses served from a stored key.
+	ReplayedHeader = "Idempotent-Replayed"
+	maxKeyLength   = 255
+)
+
+// Middleware makes POST, PUT, PATCH and DELETE requests that carry an
+// Idempotency-Key header safe to retry. The first request with a key runs
+// normally and its response is stored for ttl; retries with the same key and
+// body get the stored response, and reusing the key for a different request is
+// rejected with 422. Responses with a 5xx status are not stored.
+func Middleware(repo IdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return middleware.ErrorWrapper(func(w http.ResponseWriter, r *http.Request) error {
+			keyValue := r.Header.Get(HeaderName)
+			if keyValue == "" || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+			if len(keyValue) > maxKeyLength {
+				return apperror.InvalidField(HeaderName, "Idempotency-Key must not exceed 255 characters")
+			}
+
+			principal, ok := middleware.PrincipalFromContext(r.Context())
+			if !ok {
+				return apperror.Unauthorized("authentication required")
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, common.MaxBodyBytes))
+			if err != nil {
+				return apperror.TooLarge("request body is too large")
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key, reserved, err := repo.ReserveKey(&IdempotencyKey{
+				Scope:       string(principal.Type) + ":" + principal.Subject,
+				Key:         keyValue,
+				RequestHash: requestHash(r, body),
+				ExpiresAt:   time.Now().Add(ttl),
+			})
+			if err != nil {
+				return err
+			}
+
+			if !reserved {
+				return replay(w, r, key, body)
+			}
+			return record(w, r, next, repo, key)
+		})
+	}
+}
+
+// StartCleanup deletes expired keys every interval until the process exits.
+func StartCleanup(repo IdempotencyRepository, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			if _, err := repo.DeleteExpiredKeys(now); err != nil {
+				log.Printf("❌ Failed to delete expired idempotency keys: %v", err)
+			}
+		}
+	}()
+}
+
+// -------------------- helpers ----------------
+
+func replay(w http.ResponseWriter, r *http.Request, key *IdempotencyKey, body []byte) error {
+	if key.RequestHash != requestHash(r, body) {
+		return apperror.Unprocessable("Idempotency-Key was already used for a different request").
+			WithCode("idempotency_key_reused")
+	}
+	if !key.IsCompleted() {
+		return apperror.Conflict("a request with this Idempotency-Key is still being processed").
+			WithCode("idempotency_key_in_use")
+	}
+
+	for name, values := range key.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(key.StatusCode)
+	w.Write(key.Body)
+	return nil
+}
+
+func record(w http.ResponseWriter, r *http.Request, next http.Handler, repo IdempotencyRepository, key *IdempotencyKey) error {
+	recorder := &responseRecorder{ResponseWriter: w}
+	completed := false
+	defer func() {
+		if !completed {
+			if err := repo.ReleaseKey(key.Scope, key.Key); err != nil {
+				log.Printf("❌ Failed to release idempotency key: %v", err)
+			}
+		}
+	}()
+
+	next.ServeHTTP(recorder, r)
+
+	if recorder.statusCode == 0 {
+		recorder.statusCode = http.StatusOK
+	}
+	if recorder.statusCode >= http.StatusInternalServerError {
+		return nil
+	}
+
+	completedAt := time.Now()
+	key.StatusCode = recorder.statusCode
+	key.Header = Header(w.Header().Clone())
+	key.Body = recorder.body.Bytes()
+	key.CompletedAt = &completedAt
+
+	if err := repo.CompleteKey(key); err != nil {
+		// The response is already sent, so the key is released for a retry.
+		log.Printf("❌ Failed to store idempotent response: %v", err)
+		return nil
+	}
+	completed = true
+	return nil
+}
+
+// requestHash fingerprints the method, target and body of a request.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
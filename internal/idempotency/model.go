@@ -0,0 +1,58 @@
+package idempotency
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey remembers the outcome of a mutating request sent with an
+// Idempotency-Key header. Keys are scoped to the client that sent them, and
+// the response is kept until ExpiresAt so retries can be answered with it.
+type IdempotencyKey struct {
+	Scope       string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey"`
+	RequestHash string `gorm:"not null"`
+	StatusCode  int    `gorm:"not null;default:0"`
+	Header      Header `gorm:"type:jsonb"`
+	Body        []byte `gorm:"type:bytea"`
+	CompletedAt *time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// IsCompleted reports whether the response of the original request is stored.
+// Keys that are not completed belong to a request still being processed.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.CompletedAt != nil
+}
+
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// Header is stored as a JSON object.
+type Header http.Header
+
+func (h Header) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return json.Marshal(h)
+}
+
+func (h *Header) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	case []byte:
+		return json.Unmarshal(v, h)
+	default:
+		return fmt.Errorf("cannot scan type %T into Header", value)
+	}
+}
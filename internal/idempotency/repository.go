@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	ReserveKey(key *IdempotencyKey) (*IdempotencyKey, bool, error)
+	CompleteKey(key *IdempotencyKey) error
+	ReleaseKey(scope, key string) error
+	DeleteExpiredKeys(now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+// ReserveKey inserts key unless the client already used it. It returns the
+// stored key and false when the key is taken, or key and true when the caller
+// now owns it. An expired key is replaced as if it was never used.
+func (r *idempotencyRepository) ReserveKey(key *IdempotencyKey) (*IdempotencyKey, bool, error) {
+	err := r.db.Where("scope = ? AND key = ? AND expires_at <= ?", key.Scope, key.Key, time.Now()).
+		Delete(&IdempotencyKey{}).Error
+	if err != nil {
+		return nil, false, apperror.Database(err, "idempotency key")
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return nil, false, apperror.Database(result.Error, "idempotency key")
+	}
+	if result.RowsAffected == 1 {
+		return key, true, nil
+	}
+
+	var existing IdempotencyKey
+	if err := r.db.First(&existing, "scope = ? AND key = ?", key.Scope, key.Key).Error; err != nil {
+		return nil, false, apperror.Database(err, "idempotency key")
+	}
+	return &existing, false, nil
+}
+
+// CompleteKey stores the response of the request that reserved the key.
+func (r *idempotencyRepository) CompleteKey(key *IdempotencyKey) error {
+	err := r.db.Model(&IdempotencyKey{}).
+		Where("scope = ? AND key = ?", key.Scope, key.Key).
+		Updates(map[string]interface{}{
+			"status_code":  key.StatusCode,
+			"header":       key.Header,
+			"body":         key.Body,
+			"completed_at": key.CompletedAt,
+		}).Error
+	return apperror.Database(err, "idempotency key")
+}
+
+// ReleaseKey forgets a key whose request failed, so it can be retried.
+func (r *idempotencyRepository) ReleaseKey(scope, key string) error {
+	err := r.db.Where("scope = ? AND key = ?", scope, key).Delete(&IdempotencyKey{}).Error
+	return apperror.Database(err, "idempotency key")
+}
+
+func (r *idempotencyRepository) DeleteExpiredKeys(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&IdempotencyKey{})
+	if result.Error != nil {
+		return 0, apperror.Database(result.Error, "idempotency key")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillingCycle is how often the subscription price is charged.
+type BillingCycle string
+
+const (
+	BillingCycleWeekly    BillingCycle = "weekly"
+	BillingCycleMonthly   BillingCycle = "monthly"
+	BillingCycleQuarterly BillingCycle = "quarterly"
+	BillingCycleYearly    BillingCycle = "yearly"
+)
+
+func (c BillingCycle) IsValid() bool {
+	switch c {
+	case BillingCycleWeekly, BillingCycleMonthly, BillingCycleQuarterly, BillingCycleYearly:
+		return true
+	}
+	return false
+}
+
+func (c BillingCycle) Validate() error {
+	if !c.IsValid() {
+		return fmt.Errorf("invalid billing cycle %q (expected weekly, monthly, quarterly or yearly)", c)
+	}
+	return nil
+}
+
+// chargesIn returns how many times a price billed on this cycle since start is
+// charged during month.
+func (c BillingCycle) chargesIn(start, month MonthYear) int {
+	elapsed := monthsBetween(start, month)
+	if elapsed < 0 {
+		return 0
+	}
+
+	switch c {
+	case BillingCycleWeekly:
+		return weeklyChargesIn(start, month)
+	case BillingCycleQuarterly:
+		if elapsed%3 == 0 {
+			return 1
+		}
+		return 0
+	case BillingCycleYearly:
+		if elapsed%12 == 0 {
+			return 1
+		}
+		return 0
+	default:
+		return 1
+	}
+}
+
+// perMonth returns the ratio that converts a price billed on this cycle into
+// its average monthly cost.
+func (c BillingCycle) perMonth() (numerator, denominator int) {
+	switch c {
+	case BillingCycleWeekly:
+		return 52, 12
+	case BillingCycleQuarterly:
+		return 1, 3
+	case BillingCycleYearly:
+		return 1, 12
+	default:
+		return 1, 1
+	}
+}
+
+// normalizedAmount returns the share of price attributed to month when a
+// cycle's price is spread evenly over its months. Rounding is cumulative
+// since start, so the shares of a full cycle add up to the exact price.
+func (c BillingCycle) normalizedAmount(price int, start, month MonthYear) int {
+	elapsed := monthsBetween(start, month)
+	if elapsed < 0 {
+		return 0
+	}
+
+	numerator, denominator := c.perMonth()
+	accrued := func(months int) int {
+		return roundDiv(price*numerator*months, denominator)
+	}
+	return accrued(elapsed+1) - accrued(elapsed)
+}
+
+// weeklyChargesIn counts the weekly charge dates, starting on the first day
+// of start, that fall within month.
+func weeklyChargesIn(start, month MonthYear) int {
+	const week = 7 * 24 * time.Hour
+
+	monthStart := month.ToTime()
+	nextMonth := month.AddMonths(1).ToTime()
+
+	offset := monthStart.Sub(start.ToTime())
+	first := monthStart
+	if rest := offset % week; rest != 0 {
+		first = monthStart.Add(week - rest)
+	}
+
+	if !first.Before(nextMonth) {
+		return 0
+	}
+	return int((nextMonth.Sub(first)-1)/week) + 1
+}
+
+func roundDiv(numerator, denominator int) int {
+	if numerator < 0 {
+		return -roundDiv(-numerator, denominator)
+	}
+	return (numerator + denominator/2) / denominator
+}
@@ -0,0 +1,111 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChargesIn(t *testing.T) {
+	start := month(2025, time.January)
+
+	tests := []struct {
+		cycle BillingCycle
+		month MonthYear
+		want  int
+	}{
+		{BillingCycleMonthly, month(2024, time.December), 0},
+		{BillingCycleMonthly, start, 1},
+		{BillingCycleMonthly, month(2025, time.February), 1},
+
+		{BillingCycleQuarterly, month(2024, time.December), 0},
+		{BillingCycleQuarterly, start, 1},
+		{BillingCycleQuarterly, month(2025, time.February), 0},
+		{BillingCycleQuarterly, month(2025, time.March), 0},
+		{BillingCycleQuarterly, month(2025, time.April), 1},
+		{BillingCycleQuarterly, month(2026, time.January), 1},
+
+		{BillingCycleYearly, month(2024, time.December), 0},
+		{BillingCycleYearly, start, 1},
+		{BillingCycleYearly, month(2025, time.December), 0},
+		{BillingCycleYearly, month(2026, time.January), 1},
+
+		// Weekly charges fall on Jan 1, 8, 15, 22 and 29, then Feb 5, 12,
+		// 19 and 26, then Apr 2, 9, 16, 23 and 30.
+		{BillingCycleWeekly, month(2024, time.December), 0},
+		{BillingCycleWeekly, start, 5},
+		{BillingCycleWeekly, month(2025, time.February), 4},
+		{BillingCycleWeekly, month(2025, time.April), 5},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cycle.chargesIn(start, tt.month); got != tt.want {
+			t.Errorf("%s chargesIn(%s) = %d, want %d", tt.cycle, tt.month, got, tt.want)
+		}
+	}
+}
+
+func TestWeeklyChargesAddUpOverAYear(t *testing.T) {
+	start := month(2025, time.January)
+
+	total := 0
+	for m := start; m.ToTime().Before(month(2026, time.January).ToTime()); m = m.AddMonths(1) {
+		total += BillingCycleWeekly.chargesIn(start, m)
+	}
+	// Jan 1 and Dec 31, 2025 are both Wednesdays.
+	if total != 53 {
+		t.Errorf("weekly charges in 2025 = %d, want 53", total)
+	}
+}
+
+func TestNormalizedAmountsAddUpToCyclePrice(t *testing.T) {
+	tests := []struct {
+		cycle  BillingCycle
+		price  int
+		months int
+		want   int
+	}{
+		{BillingCycleMonthly, 999, 1, 999},
+		{BillingCycleQuarterly, 100, 3, 100},
+		{BillingCycleQuarterly, 100, 6, 200},
+		{BillingCycleYearly, 1000, 12, 1000},
+		{BillingCycleYearly, 1000, 24, 2000},
+		{BillingCycleYearly, 7, 12, 7},
+		{BillingCycleWeekly, 1000, 12, 52000},
+	}
+
+	start := month(2025, time.January)
+	for _, tt := range tests {
+		total := 0
+		for i := 0; i < tt.months; i++ {
+			total += tt.cycle.normalizedAmount(tt.price, start, start.AddMonths(i))
+		}
+		if total != tt.want {
+			t.Errorf("%s normalized amounts of %d over %d months = %d, want %d", tt.cycle, tt.price, tt.months, total, tt.want)
+		}
+	}
+}
+
+func TestNormalizedAmountSpreadsRemainders(t *testing.T) {
+	start := month(2025, time.January)
+
+	tests := []struct {
+		cycle BillingCycle
+		price int
+		want  []int
+	}{
+		{BillingCycleQuarterly, 100, []int{33, 34, 33}},
+		{BillingCycleYearly, 1000, []int{83, 84, 83, 83, 84, 83, 83, 84, 83, 83, 84, 83}},
+	}
+
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := tt.cycle.normalizedAmount(tt.price, start, start.AddMonths(i)); got != want {
+				t.Errorf("%s normalizedAmount(%d) in month %d = %d, want %d", tt.cycle, tt.price, i+1, got, want)
+			}
+		}
+	}
+
+	if got := BillingCycleYearly.normalizedAmount(1000, start, month(2024, time.December)); got != 0 {
+		t.Errorf("normalizedAmount before start = %d, want 0", got)
+	}
+}
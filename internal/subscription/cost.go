@@ -0,0 +1,225 @@
+package subscription
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Keys the total price can be grouped by.
+const (
+	groupByCategory = "category"
+	groupByTag      = "tag"
+)
+
+// monthlyCharge is the amount a subscription costs in a single calendar month
+// at its list price and the part of it taken off by discounts, along with the
+// price of a whole billing cycle in that month before and after discounts.
+type monthlyCharge struct {
+	Month     MonthYear
+	ListPrice int
+	Discount  int
+
+	cycleListPrice int
+	cycleNetPrice  int
+}
+
+// activePeriod returns the first and last month in which the subscription is
+// active within [from, to]. An open-ended subscription is treated as ongoing
+// until to. ok is false when the subscription does not overlap the window.
+func (s *Subscription) activePeriod(from, to MonthYear) (first, last MonthYear, ok bool) {
+	first = toMonth(s.StartDate.ToTime())
+	if first.ToTime().Before(from.ToTime()) {
+		first = from
+	}
+
+	last = to
+	if s.EndDate != nil {
+		end := toMonth(s.EndDate.ToTime())
+		if end.ToTime().Before(last.ToTime()) {
+			last = end
+		}
+	}
+
+	if last.ToTime().Before(first.ToTime()) {
+		return first, last, false
+	}
+	return first, last, true
+}
+
+// charges returns the amount charged in every month the subscription is
+// active within [from, to], following its billing cycle at the price effective
+// in that month, and the discount on it. Trial, paused and cancelled months are
+// skipped, and cycles are counted from the first paid month, so a trial does
+// not swallow the first charge of a longer cycle. When normalized is set, each
+// cycle's price is spread evenly over its months instead.
+func (s *Subscription) charges(from, to MonthYear, normalized bool) []monthlyCharge {
+	first, last, ok := s.activePeriod(from, to)
+	if !ok {
+		return nil
+	}
+
+	start := s.billingStart()
+	cycle := s.BillingCycle
+	if cycle == "" {
+		cycle = BillingCycleMonthly
+	}
+
+	charges := make([]monthlyCharge, 0, monthsBetween(first, last)+1)
+	for month := first; !month.ToTime().After(last.ToTime()); month = month.AddMonths(1) {
+		if !s.statusIn(month).isBillable() {
+			continue
+		}
+		price := s.priceIn(month)
+		amount := func(price int) int {
+			if normalized {
+				return cycle.normalizedAmount(price, start, month)
+			}
+			return price * cycle.chargesIn(start, month)
+		}
+
+		listPrice := amount(price)
+		if listPrice != 0 {
+			netPrice := s.discountedPriceIn(month, price)
+			net := amount(netPrice)
+			charges = append(charges, monthlyCharge{
+				Month:          month,
+				ListPrice:      listPrice,
+				Discount:       listPrice - net,
+				cycleListPrice: price,
+				cycleNetPrice:  netPrice,
+			})
+		}
+	}
+	return charges
+}
+
+// newCostBreakdown creates an empty breakdown with one bucket per month of
+// [from, to].
+func newCostBreakdown(from, to MonthYear, currencyCode string) *CostBreakdownDTO {
+	breakdown := &CostBreakdownDTO{
+		From:     from,
+		To:       to,
+		Currency: currencyCode,
+		Months:   make([]MonthCostDTO, 0, monthsBetween(from, to)+1),
+		Services: []ServiceCostDTO{},
+	}
+	for month := from; !month.ToTime().After(to.ToTime()); month = month.AddMonths(1) {
+		breakdown.Months = append(breakdown.Months, MonthCostDTO{Month: month, Services: []ServiceCostDTO{}})
+	}
+	return breakdown
+}
+
+// addCharge accrues a list price and its discount to the bucket of month, the
+// service totals and the grand total.
+func (b *CostBreakdownDTO) addCharge(serviceName string, month MonthYear, listPrice, discount int) {
+	bucket := &b.Months[monthsBetween(b.From, month)]
+	bucket.add(listPrice, discount)
+	bucket.Services = addServiceCost(bucket.Services, serviceName, listPrice, discount)
+
+	b.add(listPrice, discount)
+	b.Services = addServiceCost(b.Services, serviceName, listPrice, discount)
+}
+
+// sortServices orders service totals by name so responses are stable.
+func (b *CostBreakdownDTO) sortServices() {
+	sortServiceCosts(b.Services)
+	for i := range b.Months {
+		sortServiceCosts(b.Months[i].Services)
+	}
+}
+
+func addServiceCost(costs []ServiceCostDTO, serviceName string, listPrice, discount int) []ServiceCostDTO {
+	for i := range costs {
+		if costs[i].ServiceName == serviceName {
+			costs[i].add(listPrice, discount)
+			return costs
+		}
+	}
+	cost := ServiceCostDTO{ServiceName: serviceName}
+	cost.add(listPrice, discount)
+	return append(costs, cost)
+}
+
+func sortServiceCosts(costs []ServiceCostDTO) {
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].ServiceName < costs[j].ServiceName
+	})
+}
+
+// costGroups returns the groups the cost of the subscription counts towards:
+// its category, or each of its tags. Without any the subscription falls into
+// the ungrouped group.
+func (s *Subscription) costGroups(groupBy string) []GroupCostDTO {
+	if groupBy == groupByCategory {
+		if s.Category == nil {
+			return []GroupCostDTO{{}}
+		}
+		return []GroupCostDTO{{ID: &s.Category.ID, Name: s.Category.Name}}
+	}
+
+	if len(s.Tags) == 0 {
+		return []GroupCostDTO{{}}
+	}
+	groups := make([]GroupCostDTO, 0, len(s.Tags))
+	for i := range s.Tags {
+		groups = append(groups, GroupCostDTO{ID: &s.Tags[i].ID, Name: s.Tags[i].Name})
+	}
+	return groups
+}
+
+// addGroupCost accrues a list price and its discount to each of targets,
+// adding the groups that are not in costs yet.
+func addGroupCost(costs []GroupCostDTO, targets []GroupCostDTO, listPrice, discount int) []GroupCostDTO {
+	for _, target := range targets {
+		found := false
+		for i := range costs {
+			if sameGroup(costs[i].ID, target.ID) {
+				costs[i].add(listPrice, discount)
+				found = true
+				break
+			}
+		}
+		if !found {
+			target.add(listPrice, discount)
+			costs = append(costs, target)
+		}
+	}
+	return costs
+}
+
+func sameGroup(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sortGroupCosts orders groups by name, the ungrouped one first.
+func sortGroupCosts(costs []GroupCostDTO) {
+	sort.SliceStable(costs, func(i, j int) bool {
+		return costs[i].Name < costs[j].Name
+	})
+}
+
+func addUserCost(costs []UserCostDTO, userId uuid.UUID, listPrice, discount int) []UserCostDTO {
+	for i := range costs {
+		if costs[i].UserID == userId {
+			costs[i].add(listPrice, discount)
+			return costs
+		}
+	}
+	cost := UserCostDTO{UserID: userId}
+	cost.add(listPrice, discount)
+	return append(costs, cost)
+}
+
+// sortUserCosts orders users by what they pay, the highest first.
+func sortUserCosts(costs []UserCostDTO) {
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Total != costs[j].Total {
+			return costs[i].Total > costs[j].Total
+		}
+		return costs[i].UserID.String() < costs[j].UserID.String()
+	})
+}
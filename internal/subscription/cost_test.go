@@ -0,0 +1,158 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+)
+
+func month(year int, m time.Month) MonthYear {
+	return MonthYear(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func trialSubscription(cycle BillingCycle, trialEnd MonthYear, changes ...StatusChange) *Subscription {
+	start := month(2025, time.January)
+	return &Subscription{
+		Price:         1200,
+		BillingCycle:  cycle,
+		StartDate:     start,
+		TrialEnd:      &trialEnd,
+		StatusChanges: append([]StatusChange{{Status: StatusTrial, EffectiveFrom: start}}, changes...),
+	}
+}
+
+func chargedMonths(charges []monthlyCharge) map[string]int {
+	months := make(map[string]int, len(charges))
+	for _, charge := range charges {
+		months[charge.Month.String()] = charge.ListPrice
+	}
+	return months
+}
+
+func assertChargedMonths(t *testing.T, charges []monthlyCharge, want map[string]int) {
+	t.Helper()
+	got := chargedMonths(charges)
+	if len(got) != len(want) {
+		t.Fatalf("charged months = %v, want %v", got, want)
+	}
+	for m, price := range want {
+		if got[m] != price {
+			t.Errorf("charge in %s = %d, want %d (all charges %v)", m, got[m], price, got)
+		}
+	}
+}
+
+func TestChargesCountCyclesFromConversionMonth(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription *Subscription
+		want         map[string]int
+	}{
+		{
+			name:         "yearly with a one month trial",
+			subscription: trialSubscription(BillingCycleYearly, month(2025, time.January)),
+			want:         map[string]int{"02-2025": 1200},
+		},
+		{
+			name:         "quarterly with a one month trial",
+			subscription: trialSubscription(BillingCycleQuarterly, month(2025, time.January)),
+			want:         map[string]int{"02-2025": 1200, "05-2025": 1200, "08-2025": 1200, "11-2025": 1200},
+		},
+		{
+			name: "quarterly with a trial ended early",
+			subscription: trialSubscription(BillingCycleQuarterly, month(2025, time.March),
+				StatusChange{Status: StatusActive, EffectiveFrom: month(2025, time.February)}),
+			want: map[string]int{"02-2025": 1200, "05-2025": 1200, "08-2025": 1200, "11-2025": 1200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertChargedMonths(t, tt.subscription.charges(month(2025, time.January), month(2025, time.December), false), tt.want)
+		})
+	}
+}
+
+func TestNormalizedChargesStartAtConversionMonth(t *testing.T) {
+	subscription := trialSubscription(BillingCycleYearly, month(2025, time.January))
+
+	charges := subscription.charges(month(2025, time.January), month(2025, time.December), true)
+	if len(charges) != 11 {
+		t.Fatalf("got %d normalized charges, want 11", len(charges))
+	}
+	for _, charge := range charges {
+		if charge.ListPrice != 100 {
+			t.Errorf("normalized charge in %s = %d, want 100", charge.Month, charge.ListPrice)
+		}
+	}
+}
+
+func endMonth(year int, m time.Month) *MonthYear {
+	end := month(year, m)
+	return &end
+}
+
+func TestChargesCoverActiveMonthsWithinWindow(t *testing.T) {
+	from, to := month(2025, time.January), month(2025, time.June)
+
+	tests := []struct {
+		name         string
+		subscription *Subscription
+		want         map[string]int
+	}{
+		{
+			name:         "starts and ends within the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2025, time.February), EndDate: endMonth(2025, time.April)},
+			want:         map[string]int{"02-2025": 500, "03-2025": 500, "04-2025": 500},
+		},
+		{
+			name:         "open-ended subscription is clipped to the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2024, time.March)},
+			want: map[string]int{
+				"01-2025": 500, "02-2025": 500, "03-2025": 500,
+				"04-2025": 500, "05-2025": 500, "06-2025": 500,
+			},
+		},
+		{
+			name:         "ends in the first month of the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2024, time.March), EndDate: endMonth(2025, time.January)},
+			want:         map[string]int{"01-2025": 500},
+		},
+		{
+			name:         "starts in the last month of the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2025, time.June)},
+			want:         map[string]int{"06-2025": 500},
+		},
+		{
+			name:         "ends before the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2024, time.March), EndDate: endMonth(2024, time.December)},
+			want:         map[string]int{},
+		},
+		{
+			name:         "starts after the window",
+			subscription: &Subscription{Price: 500, StartDate: month(2025, time.July)},
+			want:         map[string]int{},
+		},
+		{
+			name: "price changes apply from their month",
+			subscription: &Subscription{Price: 500, StartDate: month(2025, time.January), EndDate: endMonth(2025, time.April),
+				PriceChanges: []PriceChange{{EffectiveFrom: month(2025, time.March), Price: 700}}},
+			want: map[string]int{"01-2025": 500, "02-2025": 500, "03-2025": 700, "04-2025": 700},
+		},
+		{
+			name: "paused months are not charged",
+			subscription: &Subscription{Price: 500, StartDate: month(2025, time.January), EndDate: endMonth(2025, time.April),
+				StatusChanges: []StatusChange{
+					{Status: StatusActive, EffectiveFrom: month(2025, time.January)},
+					{Status: StatusPaused, EffectiveFrom: month(2025, time.February)},
+					{Status: StatusActive, EffectiveFrom: month(2025, time.April)},
+				}},
+			want: map[string]int{"01-2025": 500, "04-2025": 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertChargedMonths(t, tt.subscription.charges(from, to, false), tt.want)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiscountKind says how a discount's Value reduces the price.
+type DiscountKind string
+
+const (
+	// DiscountPercent takes Value percent off the price.
+	DiscountPercent DiscountKind = "percent"
+	// DiscountFixed takes Value, in the subscription's currency, off the price.
+	DiscountFixed DiscountKind = "fixed"
+)
+
+// Discount reduces the price of a subscription in every month from StartMonth
+// through EndMonth, or indefinitely without an end.
+type Discount struct {
+	ID             uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	SubscriptionID uuid.UUID    `gorm:"type:uuid;not null;index;<-:create" json:"-"`
+	Kind           DiscountKind `gorm:"not null" json:"kind"`
+	Value          int          `gorm:"not null" json:"value"`
+	StartMonth     MonthYear    `gorm:"not null" json:"start_month"`
+	EndMonth       *MonthYear   `json:"end_month,omitempty"`
+	Description    string       `json:"description,omitempty"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime;<-:create" json:"created_at"`
+}
+
+func (d *Discount) appliesIn(month MonthYear) bool {
+	if month.ToTime().Before(toMonth(d.StartMonth.ToTime()).ToTime()) {
+		return false
+	}
+	return d.EndMonth == nil || !month.ToTime().After(toMonth(d.EndMonth.ToTime()).ToTime())
+}
+
+// apply returns price reduced by the discount, never below zero.
+func (d *Discount) apply(price int) int {
+	switch d.Kind {
+	case DiscountPercent:
+		price -= price * d.Value / 100
+	case DiscountFixed:
+		price -= d.Value
+	}
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// discountedPriceIn returns price after every discount effective in month.
+// Overlapping discounts stack in the order they were added.
+func (s *Subscription) discountedPriceIn(month MonthYear, price int) int {
+	for i := range s.Discounts {
+		if s.Discounts[i].appliesIn(month) {
+			price = s.Discounts[i].apply(price)
+		}
+	}
+	return price
+}
@@ -0,0 +1,113 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiscountAppliesIn(t *testing.T) {
+	bounded := Discount{StartMonth: month(2025, time.March), EndMonth: endMonth(2025, time.May)}
+	open := Discount{StartMonth: month(2025, time.March)}
+
+	tests := []struct {
+		name     string
+		discount Discount
+		month    MonthYear
+		want     bool
+	}{
+		{"before start", bounded, month(2025, time.February), false},
+		{"start month", bounded, month(2025, time.March), true},
+		{"end month", bounded, month(2025, time.May), true},
+		{"after end", bounded, month(2025, time.June), false},
+		{"open-ended before start", open, month(2025, time.February), false},
+		{"open-ended years later", open, month(2030, time.January), true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.discount.appliesIn(tt.month); got != tt.want {
+			t.Errorf("%s: appliesIn(%s) = %v, want %v", tt.name, tt.month, got, tt.want)
+		}
+	}
+}
+
+func TestDiscountApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount Discount
+		price    int
+		want     int
+	}{
+		{"percent", Discount{Kind: DiscountPercent, Value: 25}, 1000, 750},
+		{"percent rounds the discount down", Discount{Kind: DiscountPercent, Value: 15}, 599, 510},
+		{"full percent", Discount{Kind: DiscountPercent, Value: 100}, 599, 0},
+		{"fixed", Discount{Kind: DiscountFixed, Value: 200}, 1000, 800},
+		{"fixed above the price", Discount{Kind: DiscountFixed, Value: 1500}, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.discount.apply(tt.price); got != tt.want {
+			t.Errorf("%s: apply(%d) = %d, want %d", tt.name, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestDiscountsStackInOrder(t *testing.T) {
+	start := month(2025, time.January)
+	percent := Discount{Kind: DiscountPercent, Value: 50, StartMonth: start}
+	fixed := Discount{Kind: DiscountFixed, Value: 100, StartMonth: start}
+
+	percentFirst := &Subscription{Discounts: []Discount{percent, fixed}}
+	if got := percentFirst.discountedPriceIn(start, 1000); got != 400 {
+		t.Errorf("percent then fixed = %d, want 400", got)
+	}
+	fixedFirst := &Subscription{Discounts: []Discount{fixed, percent}}
+	if got := fixedFirst.discountedPriceIn(start, 1000); got != 450 {
+		t.Errorf("fixed then percent = %d, want 450", got)
+	}
+}
+
+func TestChargesWithDiscountExpiringMidWindow(t *testing.T) {
+	subscription := &Subscription{
+		Price:        1000,
+		BillingCycle: BillingCycleMonthly,
+		StartDate:    month(2025, time.January),
+		Discounts: []Discount{
+			{Kind: DiscountPercent, Value: 20, StartMonth: month(2025, time.February), EndMonth: endMonth(2025, time.March)},
+		},
+	}
+
+	want := map[string]int{"01-2025": 0, "02-2025": 200, "03-2025": 200, "04-2025": 0, "05-2025": 0}
+	charges := subscription.charges(month(2025, time.January), month(2025, time.May), false)
+	if len(charges) != len(want) {
+		t.Fatalf("got %d charges, want %d", len(charges), len(want))
+	}
+	for _, charge := range charges {
+		if charge.ListPrice != 1000 {
+			t.Errorf("list price in %s = %d, want 1000", charge.Month, charge.ListPrice)
+		}
+		if charge.Discount != want[charge.Month.String()] {
+			t.Errorf("discount in %s = %d, want %d", charge.Month, charge.Discount, want[charge.Month.String()])
+		}
+	}
+}
+
+func TestNormalizedChargesWithDiscountExpiringMidCycle(t *testing.T) {
+	subscription := &Subscription{
+		Price:        1200,
+		BillingCycle: BillingCycleQuarterly,
+		StartDate:    month(2025, time.January),
+		Discounts: []Discount{
+			{Kind: DiscountFixed, Value: 300, StartMonth: month(2025, time.January), EndMonth: endMonth(2025, time.February)},
+		},
+	}
+
+	want := map[string]int{"01-2025": 100, "02-2025": 100, "03-2025": 0}
+	for _, charge := range subscription.charges(month(2025, time.January), month(2025, time.March), true) {
+		if charge.ListPrice != 400 {
+			t.Errorf("normalized list price in %s = %d, want 400", charge.Month, charge.ListPrice)
+		}
+		if charge.Discount != want[charge.Month.String()] {
+			t.Errorf("normalized discount in %s = %d, want %d", charge.Month, charge.Discount, want[charge.Month.String()])
+		}
+	}
+}
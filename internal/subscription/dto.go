@@ -1,29 +1,311 @@
 package subscription
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
+)
+
+// SubscriptionCreateDTO creates a subscription. With catalog_entry_id, or a
+// service_name matching a catalog entry, the subscription takes the entry's
+// name, and its default currency and price when those are omitted.
 type SubscriptionCreateDTO struct {
-	ServiceName string     `json:"service_name"`
-	Price       int        `json:"price"`
-	UserID      uuid.UUID  `json:"user_id"`
-	StartDate   MonthYear  `json:"start_date"`
-	EndDate     *MonthYear `json:"end_date,omitempty"`
+	ServiceName    string       `json:"service_name,omitempty" validate:"required_without=CatalogEntryID,omitempty,notblank,max=255"`
+	CatalogEntryID *uuid.UUID   `json:"catalog_entry_id,omitempty"`
+	CategoryID     *uuid.UUID   `json:"category_id,omitempty"`
+	Tags           []string     `json:"tags,omitempty" validate:"max=20,dive,notblank,max=50"`
+	Price          *int         `json:"price,omitempty" validate:"omitnil,min=0,max=100000000"`
+	Currency       string       `json:"currency,omitempty" validate:"omitempty,currency"`
+	BillingCycle   BillingCycle `json:"billing_cycle,omitempty" validate:"omitempty,oneof=weekly monthly quarterly yearly"`
+	StartDate      MonthYear    `json:"start_date" validate:"required,monthyear"`
+	EndDate        *MonthYear   `json:"end_date,omitempty" validate:"omitnil,monthyear"`
+	Status         Status       `json:"status,omitempty" validate:"omitempty,oneof=trial active"`
+	TrialMonths    *int         `json:"trial_months,omitempty" validate:"omitnil,min=1,max=24"`
+	TrialEnd       *MonthYear   `json:"trial_end,omitempty" validate:"omitnil,monthyear"`
 }
 
+// SubscriptionUpdateDTO is the full representation a PUT replaces a
+// subscription with. An omitted end_date, trial_end, category_id or tags
+// removes them, and
+// an omitted catalog_entry_id matches service_name against the catalog again. PATCH
+// requests are applied to this representation of the stored subscription.
 type SubscriptionUpdateDTO struct {
-	ServiceName *string    `json:"service_name"`
-	Price       *int       `json:"price"`
-	StartDate   *MonthYear `json:"start_date"`
-	EndDate     *MonthYear `json:"end_date,omitempty"`
+	ServiceName    string       `json:"service_name" validate:"required,notblank,max=255"`
+	CatalogEntryID *uuid.UUID   `json:"catalog_entry_id,omitempty"`
+	CategoryID     *uuid.UUID   `json:"category_id,omitempty"`
+	Tags           []string     `json:"tags,omitempty" validate:"max=20,dive,notblank,max=50"`
+	Price          *int         `json:"price" validate:"required,min=0,max=100000000"`
+	Currency       string       `json:"currency" validate:"required,currency"`
+	BillingCycle   BillingCycle `json:"billing_cycle" validate:"required,oneof=weekly monthly quarterly yearly"`
+	StartDate      MonthYear    `json:"start_date" validate:"required,monthyear"`
+	EndDate        *MonthYear   `json:"end_date,omitempty" validate:"omitnil,monthyear"`
+	TrialEnd       *MonthYear   `json:"trial_end,omitempty" validate:"omitnil,monthyear"`
+}
+
+// PriceChangeCreateDTO schedules a new price from the given month on.
+type PriceChangeCreateDTO struct {
+	Price         int       `json:"price" validate:"min=0,max=100000000"`
+	EffectiveFrom MonthYear `json:"effective_from" validate:"required,monthyear"`
+}
+
+// StatusChangeCreateDTO moves a subscription to a new status from the given
+// month on, the current month when omitted.
+type StatusChangeCreateDTO struct {
+	Reason        string     `json:"reason,omitempty" validate:"max=500"`
+	EffectiveFrom *MonthYear `json:"effective_from,omitempty" validate:"omitnil,monthyear"`
+}
+
+// DiscountCreateDTO attaches a discount to a subscription. Percent discounts
+// take value percent off the price, fixed ones take value off it.
+type DiscountCreateDTO struct {
+	Kind        DiscountKind `json:"kind" validate:"required,oneof=percent fixed"`
+	Value       int          `json:"value" validate:"min=1,max=100000000"`
+	StartMonth  MonthYear    `json:"start_month" validate:"required,monthyear"`
+	EndMonth    *MonthYear   `json:"end_month,omitempty" validate:"omitnil,monthyear"`
+	Description string       `json:"description,omitempty" validate:"max=255"`
+}
+
+// MemberCreateDTO shares a subscription with a user, who pays either a share
+// of the cost or a fixed amount of every billing cycle's price.
+type MemberCreateDTO struct {
+	UserID uuid.UUID `json:"user_id"`
+	Share  *int      `json:"share,omitempty" validate:"omitnil,min=0,max=1000"`
+	Amount *int      `json:"amount,omitempty" validate:"omitnil,min=1,max=100000000"`
+}
+
+// fromCreateDTOtoSubscription builds the subscription of owner described by
+// dto, linked to entry when it is not nil. Without a currency the entry's
+// default currency is used, then the owner's.
+func fromCreateDTOtoSubscription(owner *user.User, dto *SubscriptionCreateDTO, entry *catalog.CatalogEntry) *Subscription {
+	code := currency.Normalize(dto.Currency)
+	if code == "" && entry != nil {
+		code = entry.DefaultCurrency
+	}
+	if code == "" {
+		code = owner.DefaultCurrency
+	}
+
+	var price int
+	if dto.Price != nil {
+		price = *dto.Price
+	} else if entry != nil && entry.DefaultPrice != nil {
+		price = *entry.DefaultPrice
+	}
+
+	billingCycle := dto.BillingCycle
+	if billingCycle == "" {
+		billingCycle = BillingCycleMonthly
+	}
+
+	trialEnd := dto.TrialEnd
+	if dto.TrialMonths != nil {
+		end := toMonth(dto.StartDate.ToTime()).AddMonths(*dto.TrialMonths - 1)
+		trialEnd = &end
+	}
+
+	status := dto.Status
+	if status == "" {
+		status = StatusActive
+		if trialEnd != nil {
+			status = StatusTrial
+		}
+	}
+
+	subscription := &Subscription{
+		ID:           uuid.New(),
+		ServiceName:  dto.ServiceName,
+		Price:        price,
+		Currency:     code,
+		BillingCycle: billingCycle,
+		UserID:       owner.ID,
+		TenantID:     owner.TenantID,
+		StartDate:    dto.StartDate,
+		EndDate:      dto.EndDate,
+		TrialEnd:     trialEnd,
+		Status:       status,
+		StatusChanges: []StatusChange{
+			{Status: status, EffectiveFrom: toMonth(dto.StartDate.ToTime())},
+		},
+	}
+	subscription.linkCatalogEntry(entry)
+	return subscription
 }
 
-func fromCreateDTOtoSubscription(dto *SubscriptionCreateDTO) *Subscription {
-	return &Subscription{
-		ID:          uuid.New(),
-		ServiceName: dto.ServiceName,
-		Price:       dto.Price,
-		UserID:      dto.UserID,
-		StartDate:   dto.StartDate,
-		EndDate:     dto.EndDate,
+func fromSubscriptionToUpdateDTO(subscription *Subscription) SubscriptionUpdateDTO {
+	price := subscription.Price
+	return SubscriptionUpdateDTO{
+		ServiceName:    subscription.ServiceName,
+		CatalogEntryID: subscription.CatalogEntryID,
+		CategoryID:     subscription.CategoryID,
+		Tags:           subscription.tagNames(),
+		Price:          &price,
+		Currency:       subscription.Currency,
+		BillingCycle:   subscription.BillingCycle,
+		StartDate:      subscription.StartDate,
+		EndDate:        subscription.EndDate,
+		TrialEnd:       subscription.TrialEnd,
 	}
 }
+
+// ServiceFilter selects the subscriptions of one service: the ones named Name
+// and, when the name resolves to a catalog entry, the ones linked to it
+// whatever name they were stored with. A zero filter selects every service.
+type ServiceFilter struct {
+	Name           string
+	CatalogEntryID *uuid.UUID
+}
+
+// SubscriptionListQueryDTO holds the paging, sorting and filtering options of
+// the list endpoint. Optional filters are nil when not requested.
+// CatalogEntryID is the catalog entry ServiceName resolves to when it is
+// exactly a catalog name or alias; subscriptions linked to it match next to
+// the substring matches of ServiceName.
+type SubscriptionListQueryDTO struct {
+	UserID         uuid.UUID
+	Limit          int
+	Cursor         string
+	SortBy         string
+	Descending     bool
+	ServiceName    string
+	CatalogEntryID *uuid.UUID
+	MinPrice       *int
+	MaxPrice       *int
+	ActiveOn       *MonthYear
+	HasEndDate     *bool
+	CategoryID     *uuid.UUID
+	Tag            string
+}
+
+type SubscriptionPageDTO struct {
+	Items      []Subscription `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// CostQueryDTO holds the filters shared by the cost aggregation endpoints.
+// Normalized spreads every charge evenly over the months of its billing cycle.
+// Charges are converted into Currency at the rates of the month they fall in.
+// GroupBy, "category" or "tag", splits the total price into groups.
+// Organization counts the full cost of every subscription in the user's
+// tenant instead of what the user pays.
+type CostQueryDTO struct {
+	UserID       uuid.UUID
+	ServiceName  string
+	From         time.Time
+	To           time.Time
+	Normalized   bool
+	Currency     string
+	GroupBy      string
+	Organization bool
+}
+
+// TrialConversionDTO describes a trial that becomes paid on ConvertsOn, the
+// first day of its first paid month, at Price.
+type TrialConversionDTO struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ServiceName    string    `json:"service_name"`
+	Price          int       `json:"price"`
+	Currency       string    `json:"currency"`
+	TrialEnd       MonthYear `json:"trial_end"`
+	ConvertsOn     string    `json:"converts_on"`
+	DaysLeft       int       `json:"days_left"`
+}
+
+// TotalPriceDTO holds the net total price, which is the list price minus
+// discounts, and its groups when grouping was requested.
+type TotalPriceDTO struct {
+	TotalPrice int            `json:"total_price"`
+	ListPrice  int            `json:"list_price"`
+	Discount   int            `json:"discount"`
+	Currency   string         `json:"currency"`
+	Groups     []GroupCostDTO `json:"groups,omitempty"`
+}
+
+// TotalPriceResponse is the body of the total price endpoint. Data stays the
+// net total as a bare number, as it was before totals were converted between
+// currencies; the currency and the rest of the total are carried alongside.
+type TotalPriceResponse struct {
+	common.Response
+	Currency string         `json:"currency"`
+	Details  *TotalPriceDTO `json:"details"`
+}
+
+// OrganizationTotalPriceDTO is the total price of an organization and the
+// part of it each user pays for.
+type OrganizationTotalPriceDTO struct {
+	TotalPriceDTO
+	Users []UserCostDTO `json:"users"`
+}
+
+type UserCostDTO struct {
+	UserID uuid.UUID `json:"user_id"`
+	CostDTO
+}
+
+// GroupCostDTO is the cost of the subscriptions in one category or with one
+// tag. Subscriptions without a category or tags form a group without an ID.
+type GroupCostDTO struct {
+	ID   *uuid.UUID `json:"id,omitempty"`
+	Name string     `json:"name"`
+	CostDTO
+}
+
+// CostDTO is an amount before and after discounts; Total is the net amount.
+type CostDTO struct {
+	ListPrice int `json:"list_price"`
+	Discount  int `json:"discount"`
+	Total     int `json:"total"`
+}
+
+func (c *CostDTO) add(listPrice, discount int) {
+	c.ListPrice += listPrice
+	c.Discount += discount
+	c.Total += listPrice - discount
+}
+
+type ServiceCostDTO struct {
+	ServiceName string `json:"service_name"`
+	CostDTO
+}
+
+type MonthCostDTO struct {
+	Month MonthYear `json:"month"`
+	CostDTO
+	Services []ServiceCostDTO `json:"services"`
+}
+
+type CostBreakdownDTO struct {
+	From     MonthYear `json:"from"`
+	To       MonthYear `json:"to"`
+	Currency string    `json:"currency"`
+	CostDTO
+	Months   []MonthCostDTO   `json:"months"`
+	Services []ServiceCostDTO `json:"services"`
+}
+
+// SettlementDTO says who owes whom for the shared subscriptions of a user in
+// Month. Shares lists what each member owes the payer of a subscription, and
+// Debts nets those amounts per pair of users.
+type SettlementDTO struct {
+	Month    MonthYear        `json:"month"`
+	Currency string           `json:"currency"`
+	Shares   []MemberShareDTO `json:"shares"`
+	Debts    []DebtDTO        `json:"debts"`
+}
+
+type MemberShareDTO struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ServiceName    string    `json:"service_name"`
+	UserID         uuid.UUID `json:"user_id"`
+	PayerID        uuid.UUID `json:"payer_id"`
+	Amount         int       `json:"amount"`
+}
+
+type DebtDTO struct {
+	FromUserID uuid.UUID `json:"from_user_id"`
+	ToUserID   uuid.UUID `json:"to_user_id"`
+	Amount     int       `json:"amount"`
+}
@@ -0,0 +1,61 @@
+package subscription
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+// Precondition is the parsed If-Match header of a mutating request. A nil
+// precondition matches every version.
+type Precondition struct {
+	Any      bool
+	Versions []int
+}
+
+// Matches reports whether a subscription at version satisfies the precondition.
+func (p *Precondition) Matches(version int) bool {
+	if p == nil || p.Any {
+		return true
+	}
+	for _, v := range p.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ETag returns the entity tag of the subscription's current version.
+func ETag(subscription *Subscription) string {
+	return fmt.Sprintf(`"%d"`, subscription.Version)
+}
+
+// parseIfMatch parses an If-Match header. Weak tags are rejected because
+// If-Match requires strong comparison. An empty header yields nil.
+func parseIfMatch(header string) (*Precondition, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, nil
+	}
+	if header == "*" {
+		return &Precondition{Any: true}, nil
+	}
+
+	var precondition Precondition
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			return nil, apperror.InvalidField("If-Match", "If-Match does not accept weak entity tags")
+		}
+
+		version, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if err != nil || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, apperror.InvalidField("If-Match", fmt.Sprintf("invalid entity tag %s", tag))
+		}
+		precondition.Versions = append(precondition.Versions, version)
+	}
+	return &precondition, nil
+}
@@ -0,0 +1,145 @@
+package subscription
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *Precondition
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"*", &Precondition{Any: true}},
+		{`"3"`, &Precondition{Versions: []int{3}}},
+		{` "3" , "5"`, &Precondition{Versions: []int{3, 5}}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseIfMatch(tt.header)
+		if err != nil {
+			t.Errorf("parseIfMatch(%q): %v", tt.header, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseIfMatch(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParseIfMatchRejectsInvalidTags(t *testing.T) {
+	for _, header := range []string{`W/"3"`, `3`, `"3`, `3"`, `"three"`, `"3", W/"4"`, `""`, `"`} {
+		if _, err := parseIfMatch(header); apperror.KindOf(err) != apperror.KindValidation {
+			t.Errorf("parseIfMatch(%q) error = %v, want a validation error", header, err)
+		}
+	}
+}
+
+func TestPreconditionMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		precondition *Precondition
+		version      int
+		want         bool
+	}{
+		{"no header", nil, 4, true},
+		{"any version", &Precondition{Any: true}, 4, true},
+		{"current version", &Precondition{Versions: []int{4}}, 4, true},
+		{"one of several versions", &Precondition{Versions: []int{2, 4}}, 4, true},
+		{"outdated version", &Precondition{Versions: []int{3}}, 4, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.precondition.Matches(tt.version); got != tt.want {
+			t.Errorf("%s: Matches(%d) = %v, want %v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestETagRoundTrip(t *testing.T) {
+	subscription := &Subscription{Version: 7}
+
+	precondition, err := parseIfMatch(ETag(subscription))
+	if err != nil {
+		t.Fatalf("parseIfMatch(%s): %v", ETag(subscription), err)
+	}
+	if !precondition.Matches(subscription.Version) {
+		t.Errorf("ETag %s does not match its own version", ETag(subscription))
+	}
+	if precondition.Matches(subscription.Version + 1) {
+		t.Errorf("ETag %s matches a newer version", ETag(subscription))
+	}
+}
+
+func TestHandlerPrecondition(t *testing.T) {
+	tests := []struct {
+		name           string
+		requireIfMatch bool
+		header         string
+		wantKind       apperror.Kind
+		wantNil        bool
+	}{
+		{"required and missing", true, "", apperror.KindPreconditionRequired, false},
+		{"required and given", true, `"2"`, "", false},
+		{"optional and missing", false, "", "", true},
+		{"invalid tag", false, `W/"2"`, apperror.KindValidation, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSubscriptionHandler(nil, tt.requireIfMatch)
+			request := httptest.NewRequest("PUT", "/api/subscriptions/1", nil)
+			if tt.header != "" {
+				request.Header.Set("If-Match", tt.header)
+			}
+
+			precondition, err := handler.precondition(request)
+			if tt.wantKind != "" {
+				if apperror.KindOf(err) != tt.wantKind {
+					t.Fatalf("error = %v, want kind %s", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("precondition: %v", err)
+			}
+			if (precondition == nil) != tt.wantNil {
+				t.Errorf("precondition = %+v, want nil: %v", precondition, tt.wantNil)
+			}
+		})
+	}
+}
+
+// versionRepository serves a single stored subscription; every other
+// repository method is left unimplemented.
+type versionRepository struct {
+	SubscriptionRepository
+	stored Subscription
+}
+
+func (r *versionRepository) GetSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error) {
+	subscription := r.stored
+	return &subscription, nil
+}
+
+func TestGetMatchingSubscriptionChecksVersion(t *testing.T) {
+	service := &subscriptionService{repo: &versionRepository{stored: Subscription{ID: uuid.New(), Version: 4}}}
+
+	if _, err := service.getMatchingSubscription(context.Background(), uuid.New(), uuid.New(), &Precondition{Versions: []int{4}}); err != nil {
+		t.Errorf("current version: %v", err)
+	}
+	if _, err := service.getMatchingSubscription(context.Background(), uuid.New(), uuid.New(), nil); err != nil {
+		t.Errorf("without If-Match: %v", err)
+	}
+	_, err := service.getMatchingSubscription(context.Background(), uuid.New(), uuid.New(), &Precondition{Versions: []int{3}})
+	if apperror.KindOf(err) != apperror.KindPrecondition {
+		t.Errorf("outdated version error = %v, want kind %s", err, apperror.KindPrecondition)
+	}
+}
@@ -2,21 +2,27 @@ package subscription
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/patch"
 )
 
+// SubscriptionHandler serves the subscription endpoints. When requireIfMatch
+// is set, PUT, PATCH and DELETE must carry an If-Match header.
 type SubscriptionHandler struct {
 	subscriptionService SubscriptionService
+	requireIfMatch      bool
 }
 
-func NewSubscriptionHandler(subscriptionService SubscriptionService) *SubscriptionHandler {
-	return &SubscriptionHandler{subscriptionService: subscriptionService}
+func NewSubscriptionHandler(subscriptionService SubscriptionService, requireIfMatch bool) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionService: subscriptionService, requireIfMatch: requireIfMatch}
 }
 
 // -------------------- handler methods ----------------
@@ -27,16 +33,25 @@ func NewSubscriptionHandler(subscriptionService SubscriptionService) *Subscripti
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        subscription  body      SubscriptionCreateDTO  true  "Subscription data"
+// @Param        subscription     body      SubscriptionCreateDTO  true  "Subscription data"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
 // @Success      201  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /api/subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
 	var subscription SubscriptionCreateDTO
-	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+	if err := common.DecodeJSON(w, r, &subscription); err != nil {
 		return err
 	}
 
-	createdSubscription, err := h.subscriptionService.CreateSubscription(&subscription)
+	createdSubscription, err := h.subscriptionService.CreateSubscription(r.Context(), userId, &subscription)
 	if err != nil {
 		return err
 	}
@@ -48,39 +63,48 @@ func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(createdSubscription))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 	return nil
 }
 
-// GetAllSubscriptionsByUserID godoc
-// @Summary      Get all user subscriptions
-// @Description  Returns a list of all subscriptions by user-id
+// ListSubscriptions godoc
+// @Summary      List user subscriptions
+// @Description  Returns a page of the authenticated user's subscriptions with sorting and filters; pass next_cursor back as cursor to get the next page
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        user-id  query     string  true  "User ID"
+// @Param        limit         query     int     false "Page size (1-100, default 20)"
+// @Param        cursor        query     string  false "Cursor returned by the previous page"
+// @Param        sort          query     string  false "Sort key: price, start_date, service_name or created_at (default)"
+// @Param        order         query     string  false "Sort order: asc (default) or desc"
+// @Param        service-name  query     string  false "Service name substring"
+// @Param        min-price     query     int     false "Minimum price"
+// @Param        max-price     query     int     false "Maximum price"
+// @Param        active-on     query     string  false "Only subscriptions active in this month (MM-YYYY)"
+// @Param        has-end-date  query     bool    false "Only subscriptions with (true) or without (false) an end date"
+// @Param        category-id   query     string  false "Only subscriptions in the category with this ID"
+// @Param        tag           query     string  false "Only subscriptions with this tag"
+// @Param        user-id       query     string  false "User ID, required for API key clients"
 // @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /api/subscriptions [get]
-func (h *SubscriptionHandler) GetAllSubscriptionsByUserID(w http.ResponseWriter, r *http.Request) error {
-	userIdStr := r.URL.Query().Get("user-id")
-	if userIdStr == "" {
-		return errors.New("user-id query parameter is required")
-	}
-
-	userId, err := uuid.Parse(userIdStr)
+func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) error {
+	query, err := parseListQuery(r)
 	if err != nil {
-		return errors.New("invalid user-id format")
+		return err
 	}
 
-	subscriptions, err := h.subscriptionService.GetAllSubscriptionsByUserID(userId)
+	page, err := h.subscriptionService.ListSubscriptions(r.Context(), query)
 	if err != nil {
 		return err
 	}
 
 	response := common.Response{
 		Success: true,
-		Data:    subscriptions,
+		Data:    page,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -90,25 +114,34 @@ func (h *SubscriptionHandler) GetAllSubscriptionsByUserID(w http.ResponseWriter,
 
 // GetSubscriptionByID godoc
 // @Summary      Get subscription by ID
-// @Description  Returns a subscription by its ID
+// @Description  Returns a subscription of the authenticated user by its ID
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Subscription ID"
+// @Param        id       path      string  true  "Subscription ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
 // @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Header       200  {string}  ETag  "Current version of the subscription"
 // @Router       /api/subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) error {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		return errors.New("subscription ID is required")
+		return apperror.InvalidField("id", "subscription ID is required")
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return errors.New("invalid subscription ID format")
+		return apperror.InvalidField("id", "invalid subscription ID format")
 	}
 
-	subscription, err := h.subscriptionService.GetSubscriptionByID(id)
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := h.subscriptionService.GetSubscriptionByID(r.Context(), userId, id)
 	if err != nil {
 		return err
 	}
@@ -119,56 +152,81 @@ func (h *SubscriptionHandler) GetSubscriptionByID(w http.ResponseWriter, r *http
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(subscription))
 	json.NewEncoder(w).Encode(response)
 	return nil
 }
 
 // GetTotalPrice godoc
 // @Summary      Get total subscription price
-// @Description  Calculates the total price of user subscriptions for a period
+// @Description  Calculates the total price of user subscriptions for a period, charging the price once for every active month
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        user-id      query     string  true  "User ID"
-// @Param        service-name query     string  false "Service name"
-// @Param        from         query     string  false "Start date (MM-YYYY)"
-// @Param        to           query     string  false "End date (MM-YYYY)"
-// @Success      200  {object}  common.Response
+// @Param        service-name  query     string  false "Service name"
+// @Param        from          query     string  false "Start date (MM-YYYY)"
+// @Param        to            query     string  false "End date (MM-YYYY), defaults to the current month"
+// @Param        normalized    query     bool    false "Spread each billing cycle's price evenly over its months"
+// @Param        currency      query     string  false "ISO 4217 currency to convert into, defaults to the user's default currency"
+// @Param        group-by      query     string  false "Also split the total by category or tag"
+// @Param        user-id       query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  TotalPriceResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /api/subscriptions/total-price [get]
 func (h *SubscriptionHandler) GetTotalPrice(w http.ResponseWriter, r *http.Request) error {
-	userIdStr := r.URL.Query().Get("user-id")
-	serviceName := r.URL.Query().Get("service-name")
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-
-	var userId uuid.UUID
-	if userIdStr == "" {
-		return errors.New("user-id query parameter is required")
+	query, err := parseCostQuery(r)
+	if err != nil {
+		return err
 	}
+	query.GroupBy = r.URL.Query().Get("group-by")
 
-	userId, err := uuid.Parse(userIdStr)
+	totalPrice, err := h.subscriptionService.GetTotalPrice(r.Context(), query)
 	if err != nil {
-		return errors.New("invalid user-id format")
+		return err
 	}
 
-	var from, to time.Time
-	if fromStr != "" {
-		t, err := time.Parse(monthYearLayout, fromStr)
-		if err != nil {
-			return errors.New("invalid from date format")
-		}
-		from = t
+	response := TotalPriceResponse{
+		Response: common.Response{
+			Success: true,
+			Data:    totalPrice.TotalPrice,
+			Message: "total price calculated",
+		},
+		Currency: totalPrice.Currency,
+		Details:  totalPrice,
 	}
 
-	if toStr != "" {
-		t, err := time.Parse(monthYearLayout, toStr)
-		if err != nil {
-			return errors.New("invalid to date format")
-		}
-		to = t
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetOrganizationTotalPrice godoc
+// @Summary      Get organization total price
+// @Description  Calculates the total price of every subscription in the authenticated user's organization for a period and splits it by the users that pay for them; requires the admin role
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        service-name  query     string  false "Service name"
+// @Param        from          query     string  false "Start date (MM-YYYY)"
+// @Param        to            query     string  false "End date (MM-YYYY), defaults to the current month"
+// @Param        normalized    query     bool    false "Spread each billing cycle's price evenly over its months"
+// @Param        currency      query     string  false "ISO 4217 currency to convert into, defaults to the user's default currency"
+// @Param        group-by      query     string  false "Also split the total by category or tag"
+// @Param        user-id       query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      403  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/organization-total-price [get]
+func (h *SubscriptionHandler) GetOrganizationTotalPrice(w http.ResponseWriter, r *http.Request) error {
+	query, err := parseCostQuery(r)
+	if err != nil {
+		return err
 	}
+	query.GroupBy = r.URL.Query().Get("group-by")
 
-	totalPrice, err := h.subscriptionService.GetTotalPrice(userId, serviceName, from, to)
+	totalPrice, err := h.subscriptionService.GetOrganizationTotalPrice(r.Context(), query)
 	if err != nil {
 		return err
 	}
@@ -176,7 +234,45 @@ func (h *SubscriptionHandler) GetTotalPrice(w http.ResponseWriter, r *http.Reque
 	response := common.Response{
 		Success: true,
 		Data:    totalPrice,
-		Message: "total price calculated",
+		Message: "organization total price calculated",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetCostBreakdown godoc
+// @Summary      Get subscription cost breakdown
+// @Description  Splits the cost of user subscriptions for a period into monthly buckets and service totals
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        service-name  query     string  false "Service name"
+// @Param        from          query     string  false "Start date (MM-YYYY), defaults to the earliest subscription"
+// @Param        to            query     string  false "End date (MM-YYYY), defaults to the current month"
+// @Param        normalized    query     bool    false "Spread each billing cycle's price evenly over its months"
+// @Param        currency      query     string  false "ISO 4217 currency to convert into, defaults to the user's default currency"
+// @Param        user-id       query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/cost-breakdown [get]
+func (h *SubscriptionHandler) GetCostBreakdown(w http.ResponseWriter, r *http.Request) error {
+	query, err := parseCostQuery(r)
+	if err != nil {
+		return err
+	}
+
+	breakdown, err := h.subscriptionService.GetCostBreakdown(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    breakdown,
+		Message: "cost breakdown calculated",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -185,32 +281,112 @@ func (h *SubscriptionHandler) GetTotalPrice(w http.ResponseWriter, r *http.Reque
 }
 
 // UpdateSubscription godoc
-// @Summary      Update subscription
-// @Description  Updates an existing subscription
+// @Summary      Replace subscription
+// @Description  Replaces a subscription with the given representation. Omitting end_date removes the end date
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string   true  "Subscription ID"
-// @Param        subscription  body      SubscriptionUpdateDTO  true  "Subscription data"
+// @Param        id               path      string                 true  "Subscription ID"
+// @Param        subscription     body      SubscriptionUpdateDTO  true  "Subscription data"
+// @Param        If-Match         header    string                 false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
 // @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Header       200  {string}  ETag  "New version of the subscription"
 // @Router       /api/subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) error {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		return errors.New("subscription ID is required")
+		return apperror.InvalidField("id", "subscription ID is required")
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return errors.New("invalid subscription ID format")
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
 	}
 
 	var subscription SubscriptionUpdateDTO
-	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+	if err := common.DecodeJSON(w, r, &subscription); err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.UpdateSubscription(r.Context(), userId, id, precondition, &subscription)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "subscription updated",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// PatchSubscription godoc
+// @Summary      Patch subscription
+// @Description  Partially updates a subscription with a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902). In a merge patch, null removes a field, so {"end_date": null} clears the end date
+// @Tags         subscriptions
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        patch            body      object  true  "Merge patch or JSON Patch document"
+// @Param        If-Match         header    string  false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Router       /api/subscriptions/{id} [patch]
+func (h *SubscriptionHandler) PatchSubscription(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	format, err := patch.FormatFromContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	document, err := common.ReadBody(w, r)
+	if err != nil {
 		return err
 	}
 
-	updatedSubscription, err := h.subscriptionService.UpdateSubscription(id, &subscription)
+	updatedSubscription, err := h.subscriptionService.PatchSubscription(r.Context(), userId, id, precondition, format, document)
 	if err != nil {
 		return err
 	}
@@ -222,31 +398,47 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
 	json.NewEncoder(w).Encode(response)
 	return nil
 }
 
 // DeleteSubscriptionByID godoc
 // @Summary      Delete subscription
-// @Description  Deletes a subscription by its ID
+// @Description  Moves a subscription to the trash, from where it can be restored until it is purged
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Subscription ID"
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        If-Match         header    string  false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
 // @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /api/subscriptions/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscriptionByID(w http.ResponseWriter, r *http.Request) error {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		return errors.New("subscription ID is required")
+		return apperror.InvalidField("id", "subscription ID is required")
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return errors.New("invalid subscription ID format")
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
 	}
 
-	if err := h.subscriptionService.DeleteSubscriptionByID(id); err != nil {
+	if err := h.subscriptionService.DeleteSubscriptionByID(r.Context(), userId, id, precondition); err != nil {
 		return err
 	}
 
@@ -259,3 +451,893 @@ func (h *SubscriptionHandler) DeleteSubscriptionByID(w http.ResponseWriter, r *h
 	json.NewEncoder(w).Encode(response)
 	return nil
 }
+
+// SchedulePriceChange godoc
+// @Summary      Schedule price change
+// @Description  Sets a new subscription price from the current or a future month on; costs of earlier months keep the previous price
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string                true  "Subscription ID"
+// @Param        change           body      PriceChangeCreateDTO  true  "New price and the month it takes effect (MM-YYYY)"
+// @Param        If-Match         header    string                false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Header       201  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/price-changes [post]
+func (h *SubscriptionHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	var change PriceChangeCreateDTO
+	if err := common.DecodeJSON(w, r, &change); err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.SchedulePriceChange(r.Context(), userId, id, precondition, &change)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "price change scheduled",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// CancelPriceChange godoc
+// @Summary      Cancel price change
+// @Description  Removes a price change from the current or a future month
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        changeId         path      string  true  "Price change ID"
+// @Param        If-Match         header    string  false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/price-changes/{changeId} [delete]
+func (h *SubscriptionHandler) CancelPriceChange(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	changeId, err := uuid.Parse(chi.URLParam(r, "changeId"))
+	if err != nil {
+		return apperror.InvalidField("changeId", "invalid price change ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.CancelPriceChange(r.Context(), userId, id, changeId, precondition)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "price change cancelled",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// AddDiscount godoc
+// @Summary      Add discount
+// @Description  Attaches a percent or fixed discount to a subscription for a window of months; cost calculations take it off the price of every month in the window
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string             true  "Subscription ID"
+// @Param        discount         body      DiscountCreateDTO  true  "Discount kind, value and months (MM-YYYY) it applies to"
+// @Param        If-Match         header    string             false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string             false "Key that makes retries of this request safe"
+// @Param        user-id          query     string             false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Header       201  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/discounts [post]
+func (h *SubscriptionHandler) AddDiscount(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	var discount DiscountCreateDTO
+	if err := common.DecodeJSON(w, r, &discount); err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.AddDiscount(r.Context(), userId, id, precondition, &discount)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "discount added",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RemoveDiscount godoc
+// @Summary      Remove discount
+// @Description  Detaches a discount from a subscription; the months it covered are charged at the full price again
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        discountId       path      string  true  "Discount ID"
+// @Param        If-Match         header    string  false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/discounts/{discountId} [delete]
+func (h *SubscriptionHandler) RemoveDiscount(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	discountId, err := uuid.Parse(chi.URLParam(r, "discountId"))
+	if err != nil {
+		return apperror.InvalidField("discountId", "invalid discount ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.RemoveDiscount(r.Context(), userId, id, discountId, precondition)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "discount removed",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// AddMember godoc
+// @Summary      Add subscription member
+// @Description  Shares a subscription with another user, who owes the owner either a share of every charge or a fixed amount of every billing cycle's price
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string           true  "Subscription ID"
+// @Param        member           body      MemberCreateDTO  true  "Member user ID with a share or a fixed amount"
+// @Param        If-Match         header    string           false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string           false "Key that makes retries of this request safe"
+// @Param        user-id          query     string           false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Header       201  {string}  ETag  "New version of the subscription"
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/members [post]
+func (h *SubscriptionHandler) AddMember(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	var member MemberCreateDTO
+	if err := common.DecodeJSON(w, r, &member); err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.AddMember(r.Context(), userId, id, precondition, &member)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "member added",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RemoveMember godoc
+// @Summary      Remove subscription member
+// @Description  Stops sharing a subscription with a member; the owner and the other members split the cost again
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        memberId         path      string  true  "Member ID"
+// @Param        If-Match         header    string  false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/members/{memberId} [delete]
+func (h *SubscriptionHandler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	memberId, err := uuid.Parse(chi.URLParam(r, "memberId"))
+	if err != nil {
+		return apperror.InvalidField("memberId", "invalid member ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.RemoveMember(r.Context(), userId, id, memberId, precondition)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: "member removed",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetSettlement godoc
+// @Summary      Get shared subscription settlement
+// @Description  Lists what the members of the user's shared subscriptions owe their payers in a month, netted into one debt per pair of users
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        month     query     string  false "Month (MM-YYYY), defaults to the user's current month"
+// @Param        currency  query     string  false "ISO 4217 currency to convert into, defaults to the user's default currency"
+// @Param        user-id   query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/settlements [get]
+func (h *SubscriptionHandler) GetSettlement(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var month *MonthYear
+	if monthStr := r.URL.Query().Get("month"); monthStr != "" {
+		t, err := time.Parse(monthYearLayout, monthStr)
+		if err != nil {
+			return apperror.InvalidField("month", "invalid month format")
+		}
+		parsed := MonthYear(t)
+		month = &parsed
+	}
+
+	settlement, err := h.subscriptionService.GetSettlement(r.Context(), userId, month, r.URL.Query().Get("currency"))
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    settlement,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ActivateSubscription godoc
+// @Summary      Activate subscription
+// @Description  Ends the trial of a subscription; it is charged from the effective month on
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string                 true  "Subscription ID"
+// @Param        change           body      StatusChangeCreateDTO  true  "Optional reason and effective month (MM-YYYY), the current month by default"
+// @Param        If-Match         header    string                 false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/activate [post]
+func (h *SubscriptionHandler) ActivateSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeStatus(w, r, TransitionActivate, "subscription activated")
+}
+
+// PauseSubscription godoc
+// @Summary      Pause subscription
+// @Description  Pauses an active subscription; paused months are not charged
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string                 true  "Subscription ID"
+// @Param        change           body      StatusChangeCreateDTO  true  "Optional reason and effective month (MM-YYYY), the current month by default"
+// @Param        If-Match         header    string                 false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeStatus(w, r, TransitionPause, "subscription paused")
+}
+
+// ResumeSubscription godoc
+// @Summary      Resume subscription
+// @Description  Resumes a paused subscription; it is charged again from the effective month on
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string                 true  "Subscription ID"
+// @Param        change           body      StatusChangeCreateDTO  true  "Optional reason and effective month (MM-YYYY), the current month by default"
+// @Param        If-Match         header    string                 false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeStatus(w, r, TransitionResume, "subscription resumed")
+}
+
+// CancelSubscription godoc
+// @Summary      Cancel subscription
+// @Description  Cancels a subscription in trial, active or paused state; it is not charged from the effective month on and cannot be reactivated
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string                 true  "Subscription ID"
+// @Param        change           body      StatusChangeCreateDTO  true  "Optional reason and effective month (MM-YYYY), the current month by default"
+// @Param        If-Match         header    string                 false "ETag of the subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string                 false "Key that makes retries of this request safe"
+// @Param        user-id          query     string                 false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeStatus(w, r, TransitionCancel, "subscription cancelled")
+}
+
+// GetSubscriptionHistory godoc
+// @Summary      Get subscription history
+// @Description  Returns the audit entries of a subscription, newest first: who changed it, when, in which request and how each field changed
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Subscription ID"
+// @Param        limit    query     int     false "Page size (1-200, default 50)"
+// @Param        cursor   query     string  false "Cursor returned by the previous page"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/{id}/history [get]
+func (h *SubscriptionHandler) GetSubscriptionHistory(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return apperror.InvalidField("limit", "invalid limit value")
+		}
+	}
+
+	history, err := h.subscriptionService.GetSubscriptionHistory(r.Context(), userId, id, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListUpcomingTrialConversions godoc
+// @Summary      List upcoming trial conversions
+// @Description  Returns the authenticated user's free trials that become paid within the next days, soonest first, so they can be cancelled in time. Trials convert on the first day of the month after trial_end
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        within-days  query     int     false "Days to look ahead (0-365, default 7)"
+// @Param        user-id      query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/trials [get]
+func (h *SubscriptionHandler) ListUpcomingTrialConversions(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	days := defaultTrialLookaheadDays
+	if daysStr := r.URL.Query().Get("within-days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			return apperror.InvalidField("within-days", "invalid within-days value")
+		}
+	}
+
+	conversions, err := h.subscriptionService.ListUpcomingTrialConversions(r.Context(), userId, days)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    conversions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListDeletedSubscriptions godoc
+// @Summary      List deleted subscriptions
+// @Description  Returns the authenticated user's subscriptions in the trash, most recently deleted first
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/trash [get]
+func (h *SubscriptionHandler) ListDeletedSubscriptions(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := h.subscriptionService.ListDeletedSubscriptions(r.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    subscriptions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RestoreSubscription godoc
+// @Summary      Restore deleted subscription
+// @Description  Takes a subscription out of the trash
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        If-Match         header    string  false "ETag of the deleted subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Header       200  {string}  ETag  "New version of the subscription"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/trash/{id}/restore [post]
+func (h *SubscriptionHandler) RestoreSubscription(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	restoredSubscription, err := h.subscriptionService.RestoreSubscription(r.Context(), userId, id, precondition)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    restoredSubscription,
+		Message: "subscription restored",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(restoredSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// PurgeSubscription godoc
+// @Summary      Purge deleted subscription
+// @Description  Permanently deletes a subscription from the trash
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id               path      string  true  "Subscription ID"
+// @Param        If-Match         header    string  false "ETag of the deleted subscription, required unless REQUIRE_IF_MATCH=false"
+// @Param        Idempotency-Key  header    string  false "Key that makes retries of this request safe"
+// @Param        user-id          query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/subscriptions/trash/{id} [delete]
+func (h *SubscriptionHandler) PurgeSubscription(w http.ResponseWriter, r *http.Request) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.subscriptionService.PurgeSubscription(r.Context(), userId, id, precondition); err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "subscription purged",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+// precondition parses the If-Match header of a mutating request, rejecting
+// requests without one when If-Match is required.
+func (h *SubscriptionHandler) precondition(r *http.Request) (*Precondition, error) {
+	precondition, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+	if precondition == nil && h.requireIfMatch {
+		return nil, apperror.PreconditionRequired("If-Match header is required; send the ETag of the subscription")
+	}
+	return precondition, nil
+}
+
+// changeStatus applies transition to the subscription in the URL and writes
+// the updated subscription with message.
+func (h *SubscriptionHandler) changeStatus(w http.ResponseWriter, r *http.Request, transition Transition, message string) error {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return apperror.InvalidField("id", "subscription ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.InvalidField("id", "invalid subscription ID format")
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	precondition, err := h.precondition(r)
+	if err != nil {
+		return err
+	}
+
+	var change StatusChangeCreateDTO
+	if err := common.DecodeJSON(w, r, &change); err != nil {
+		return err
+	}
+
+	updatedSubscription, err := h.subscriptionService.ChangeStatus(r.Context(), userId, id, precondition, transition, &change)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    updatedSubscription,
+		Message: message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", ETag(updatedSubscription))
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+func parseListQuery(r *http.Request) (*SubscriptionListQueryDTO, error) {
+	values := r.URL.Query()
+	query := &SubscriptionListQueryDTO{
+		Cursor:      values.Get("cursor"),
+		SortBy:      values.Get("sort"),
+		ServiceName: values.Get("service-name"),
+		Tag:         values.Get("tag"),
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return nil, err
+	}
+	query.UserID = userId
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return nil, apperror.InvalidField("limit", "invalid limit value")
+		}
+		query.Limit = limit
+	}
+
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return nil, apperror.InvalidField("order", "invalid order value (expected asc or desc)")
+	}
+
+	if minPriceStr := values.Get("min-price"); minPriceStr != "" {
+		minPrice, err := strconv.Atoi(minPriceStr)
+		if err != nil {
+			return nil, apperror.InvalidField("min-price", "invalid min-price value")
+		}
+		query.MinPrice = &minPrice
+	}
+
+	if maxPriceStr := values.Get("max-price"); maxPriceStr != "" {
+		maxPrice, err := strconv.Atoi(maxPriceStr)
+		if err != nil {
+			return nil, apperror.InvalidField("max-price", "invalid max-price value")
+		}
+		query.MaxPrice = &maxPrice
+	}
+
+	if activeOnStr := values.Get("active-on"); activeOnStr != "" {
+		t, err := time.Parse(monthYearLayout, activeOnStr)
+		if err != nil {
+			return nil, apperror.InvalidField("active-on", "invalid active-on date format")
+		}
+		activeOn := MonthYear(t)
+		query.ActiveOn = &activeOn
+	}
+
+	if hasEndDateStr := values.Get("has-end-date"); hasEndDateStr != "" {
+		hasEndDate, err := strconv.ParseBool(hasEndDateStr)
+		if err != nil {
+			return nil, apperror.InvalidField("has-end-date", "invalid has-end-date value")
+		}
+		query.HasEndDate = &hasEndDate
+	}
+
+	if categoryIdStr := values.Get("category-id"); categoryIdStr != "" {
+		categoryId, err := uuid.Parse(categoryIdStr)
+		if err != nil {
+			return nil, apperror.InvalidField("category-id", "invalid category-id format")
+		}
+		query.CategoryID = &categoryId
+	}
+
+	return query, nil
+}
+
+func parseCostQuery(r *http.Request) (CostQueryDTO, error) {
+	var query CostQueryDTO
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+	normalizedStr := r.URL.Query().Get("normalized")
+	query.ServiceName = r.URL.Query().Get("service-name")
+	query.Currency = r.URL.Query().Get("currency")
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return query, err
+	}
+	query.UserID = userId
+
+	if fromStr != "" {
+		t, err := time.Parse(monthYearLayout, fromStr)
+		if err != nil {
+			return query, apperror.InvalidField("from", "invalid from date format")
+		}
+		query.From = t
+	}
+
+	if toStr != "" {
+		t, err := time.Parse(monthYearLayout, toStr)
+		if err != nil {
+			return query, apperror.InvalidField("to", "invalid to date format")
+		}
+		query.To = t
+	}
+
+	if normalizedStr != "" {
+		normalized, err := strconv.ParseBool(normalizedStr)
+		if err != nil {
+			return query, apperror.InvalidField("normalized", "invalid normalized value")
+		}
+		query.Normalized = normalized
+	}
+
+	return query, nil
+}
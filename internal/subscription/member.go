@@ -0,0 +1,80 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
+)
+
+// SubscriptionMember shares the cost of a subscription its owner pays for.
+// A member pays either a fixed Amount of every billing cycle's price or a
+// part of the rest proportional to Share.
+type SubscriptionMember struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	SubscriptionID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_subscription_members_user;<-:create" json:"-"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_subscription_members_user;index;<-:create" json:"user_id"`
+	User           *user.User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Share          *int       `json:"share,omitempty"`
+	Amount         *int       `json:"amount,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;<-:create" json:"created_at"`
+}
+
+// splitCharge divides amount, a charge of the subscription at cyclePrice per
+// billing cycle, between its owner and members. Members with a fixed amount
+// pay that part of the charge first, scaled down when the amounts exceed the
+// price. The rest is split by share, the owner having a share of 1 unless
+// listed as a member, and rounding leftovers stay with the owner.
+func (s *Subscription) splitCharge(amount, cyclePrice int) map[uuid.UUID]int {
+	split := map[uuid.UUID]int{s.UserID: amount}
+	if len(s.Members) == 0 || amount == 0 {
+		return split
+	}
+
+	fixedTotal, weightTotal := 0, 1
+	for _, member := range s.Members {
+		if member.UserID == s.UserID {
+			weightTotal--
+		}
+		if member.Amount != nil {
+			fixedTotal += *member.Amount
+		} else if member.Share != nil {
+			weightTotal += *member.Share
+		}
+	}
+
+	base := cyclePrice
+	if fixedTotal > base {
+		base = fixedTotal
+	}
+	rest := amount
+	for _, member := range s.Members {
+		if member.Amount != nil && base > 0 {
+			part := amount * *member.Amount / base
+			split[member.UserID] += part
+			rest -= part
+		}
+	}
+	for _, member := range s.Members {
+		if member.Share != nil && weightTotal > 0 {
+			split[member.UserID] += rest * *member.Share / weightTotal
+		}
+	}
+
+	owner := amount
+	for userId, part := range split {
+		if userId != s.UserID {
+			owner -= part
+		}
+	}
+	split[s.UserID] = owner
+	return split
+}
+
+// shareOf returns the part of charge that userId pays.
+func (s *Subscription) shareOf(userId uuid.UUID, charge monthlyCharge) monthlyCharge {
+	net := charge.ListPrice - charge.Discount
+	listPrice := s.splitCharge(charge.ListPrice, charge.cycleListPrice)[userId]
+	netPrice := s.splitCharge(net, charge.cycleNetPrice)[userId]
+	return monthlyCharge{Month: charge.Month, ListPrice: listPrice, Discount: listPrice - netPrice}
+}
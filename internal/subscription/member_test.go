@@ -0,0 +1,140 @@
+package subscription
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ownerID  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	memberA  = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	memberB  = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	memberC  = uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	shareOne = 1
+	shareTwo = 2
+)
+
+func fixedAmount(amount int) *int {
+	return &amount
+}
+
+func sharedSubscription(members ...SubscriptionMember) *Subscription {
+	return &Subscription{ID: uuid.New(), UserID: ownerID, Members: members}
+}
+
+func TestSplitCharge(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription *Subscription
+		amount       int
+		cyclePrice   int
+		want         map[uuid.UUID]int
+	}{
+		{
+			name:         "without members the owner pays everything",
+			subscription: sharedSubscription(),
+			amount:       999,
+			cyclePrice:   999,
+			want:         map[uuid.UUID]int{ownerID: 999},
+		},
+		{
+			name:         "equal shares leave the remainder with the owner",
+			subscription: sharedSubscription(SubscriptionMember{UserID: memberA, Share: &shareOne}),
+			amount:       999,
+			cyclePrice:   999,
+			want:         map[uuid.UUID]int{ownerID: 500, memberA: 499},
+		},
+		{
+			name:         "fixed amount of a full cycle",
+			subscription: sharedSubscription(SubscriptionMember{UserID: memberA, Amount: fixedAmount(300)}),
+			amount:       1000,
+			cyclePrice:   1000,
+			want:         map[uuid.UUID]int{ownerID: 700, memberA: 300},
+		},
+		{
+			name:         "fixed amount scales with a normalized charge",
+			subscription: sharedSubscription(SubscriptionMember{UserID: memberA, Amount: fixedAmount(300)}),
+			amount:       333,
+			cyclePrice:   1000,
+			want:         map[uuid.UUID]int{ownerID: 234, memberA: 99},
+		},
+		{
+			name: "fixed amounts above the price are scaled down",
+			subscription: sharedSubscription(
+				SubscriptionMember{UserID: memberA, Amount: fixedAmount(800)},
+				SubscriptionMember{UserID: memberB, Amount: fixedAmount(400)},
+			),
+			amount:     1000,
+			cyclePrice: 1000,
+			want:       map[uuid.UUID]int{ownerID: 1, memberA: 666, memberB: 333},
+		},
+		{
+			name: "shares split what fixed amounts leave",
+			subscription: sharedSubscription(
+				SubscriptionMember{UserID: memberA, Amount: fixedAmount(200)},
+				SubscriptionMember{UserID: memberB, Share: &shareTwo},
+			),
+			amount:     1000,
+			cyclePrice: 1000,
+			want:       map[uuid.UUID]int{ownerID: 267, memberA: 200, memberB: 533},
+		},
+		{
+			name: "owner listed as a member uses their own share",
+			subscription: sharedSubscription(
+				SubscriptionMember{UserID: ownerID, Share: &shareOne},
+				SubscriptionMember{UserID: memberA, Share: &shareOne},
+			),
+			amount:     1001,
+			cyclePrice: 1001,
+			want:       map[uuid.UUID]int{ownerID: 501, memberA: 500},
+		},
+		{
+			name:         "nothing to split",
+			subscription: sharedSubscription(SubscriptionMember{UserID: memberA, Share: &shareOne}),
+			amount:       0,
+			cyclePrice:   1000,
+			want:         map[uuid.UUID]int{ownerID: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.subscription.splitCharge(tt.amount, tt.cyclePrice)
+
+			total := 0
+			for _, part := range got {
+				total += part
+			}
+			if total != tt.amount {
+				t.Errorf("parts %v add up to %d, want %d", got, total, tt.amount)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("split = %v, want %v", got, tt.want)
+			}
+			for userId, want := range tt.want {
+				if got[userId] != want {
+					t.Errorf("part of %s = %d, want %d", userId, got[userId], want)
+				}
+			}
+		})
+	}
+}
+
+func TestShareOfSplitsListAndNetPrices(t *testing.T) {
+	subscription := sharedSubscription(SubscriptionMember{UserID: memberA, Share: &shareOne})
+	charge := monthlyCharge{ListPrice: 1000, Discount: 200, cycleListPrice: 1000, cycleNetPrice: 800}
+
+	owner := subscription.shareOf(ownerID, charge)
+	member := subscription.shareOf(memberA, charge)
+
+	if owner.ListPrice != 500 || owner.Discount != 100 {
+		t.Errorf("owner share = %d with discount %d, want 500 with 100", owner.ListPrice, owner.Discount)
+	}
+	if member.ListPrice != 500 || member.Discount != 100 {
+		t.Errorf("member share = %d with discount %d, want 500 with 100", member.ListPrice, member.Discount)
+	}
+	if owner.ListPrice+member.ListPrice != charge.ListPrice || owner.Discount+member.Discount != charge.Discount {
+		t.Errorf("shares do not add up to the charge")
+	}
+}
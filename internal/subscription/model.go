@@ -1,43 +1,119 @@
 package subscription
 
 import (
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
+	"gorm.io/gorm"
 )
 
 type Subscription struct {
-	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
-	ServiceName string     `gorm:"not null" json:"service_name"`
-	Price       int        `gorm:"not null" json:"price"`
-	UserID      uuid.UUID  `gorm:"type:uuid;not null;<-:create" json:"user_id"`
-	StartDate   MonthYear  `gorm:"not null" json:"start_date"`
-	EndDate     *MonthYear `json:"end_date,omitempty"`
-	CreatedAt   time.Time  `gorm:"autoCreateTime;<-:create" json:"created_at"`
-	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	ID             uuid.UUID             `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	ServiceName    string                `gorm:"not null" json:"service_name"`
+	CatalogEntryID *uuid.UUID            `gorm:"type:uuid;index" json:"catalog_entry_id,omitempty"`
+	CatalogEntry   *catalog.CatalogEntry `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	CategoryID     *uuid.UUID            `gorm:"type:uuid;index" json:"category_id,omitempty"`
+	Category       *category.Category    `gorm:"constraint:OnDelete:SET NULL" json:"category,omitempty"`
+	Tags           []tag.Tag             `gorm:"many2many:subscription_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	Price          int                   `gorm:"not null" json:"price"`
+	Currency       string                `gorm:"type:char(3);not null;default:RUB" json:"currency"`
+	BillingCycle   BillingCycle          `gorm:"not null;default:monthly" json:"billing_cycle"`
+	UserID         uuid.UUID             `gorm:"type:uuid;not null;<-:create" json:"user_id"`
+	User           *user.User            `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	TenantID       uuid.UUID             `gorm:"type:uuid;not null;index;<-:create" json:"tenant_id"`
+	Tenant         *tenant.Tenant        `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	StartDate      MonthYear             `gorm:"not null" json:"start_date"`
+	EndDate        *MonthYear            `json:"end_date,omitempty"`
+	TrialEnd       *MonthYear            `json:"trial_end,omitempty"`
+	Status         Status                `gorm:"-" json:"status"`
+	Version        int                   `gorm:"not null;default:1" json:"version"`
+	PriceChanges   []PriceChange         `gorm:"constraint:OnDelete:CASCADE" json:"price_changes,omitempty"`
+	StatusChanges  []StatusChange        `gorm:"constraint:OnDelete:CASCADE" json:"status_changes,omitempty"`
+	Discounts      []Discount            `gorm:"constraint:OnDelete:CASCADE" json:"discounts,omitempty"`
+	Members        []SubscriptionMember  `gorm:"constraint:OnDelete:CASCADE" json:"members,omitempty"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt      time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt        `gorm:"index" json:"deleted_at"`
 }
 
-func (s *Subscription) Validate() (err error) {
+// Validate checks every field and reports all violations at once.
+func (s *Subscription) Validate() error {
+	var fields []apperror.FieldError
+
 	if s.EndDate != nil && s.EndDate.ToTime().Before(s.StartDate.ToTime()) {
-		return errors.New("end date cannot be before start date")
+		fields = append(fields, apperror.FieldError{Field: "end_date", Message: "end date cannot be before start date"})
+	}
+
+	if s.TrialEnd != nil && s.TrialEnd.ToTime().Before(s.StartDate.ToTime()) {
+		fields = append(fields, apperror.FieldError{Field: "trial_end", Message: "trial end cannot be before start date"})
+	}
+
+	if s.TrialEnd != nil && len(s.StatusChanges) > 0 && s.StatusChanges[0].Status != StatusTrial {
+		fields = append(fields, apperror.FieldError{Field: "trial_end", Message: "trial end requires a subscription that starts with a trial"})
 	}
 
 	if s.Price < 0 {
-		return errors.New("price cannot be negative")
+		fields = append(fields, apperror.FieldError{Field: "price", Message: "price cannot be negative"})
+	}
+
+	for _, change := range s.PriceChanges {
+		if !change.EffectiveFrom.ToTime().After(s.StartDate.ToTime()) {
+			fields = append(fields, apperror.FieldError{Field: "start_date", Message: "start date must be before every price change"})
+			break
+		}
+	}
+
+	if err := currency.Validate(s.Currency); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "currency", Message: err.Error()})
+	}
+
+	if err := s.BillingCycle.Validate(); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "billing_cycle", Message: err.Error()})
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields[0].Message, fields...)
 	}
 	return nil
 }
 
-func (s *Subscription) UpdateFields(updatedData SubscriptionUpdateDTO) {
-	if updatedData.ServiceName != nil {
-		s.ServiceName = *updatedData.ServiceName
-	}
+// ReplaceFields overwrites every client-editable field with updatedData.
+func (s *Subscription) ReplaceFields(updatedData SubscriptionUpdateDTO) {
+	s.ServiceName = updatedData.ServiceName
 	if updatedData.Price != nil {
 		s.Price = *updatedData.Price
 	}
-	if updatedData.StartDate != nil {
-		s.StartDate = *updatedData.StartDate
-	}
+	s.Currency = currency.Normalize(updatedData.Currency)
+	s.BillingCycle = updatedData.BillingCycle
+	s.StartDate = updatedData.StartDate
 	s.EndDate = updatedData.EndDate
+	s.TrialEnd = updatedData.TrialEnd
+	s.CategoryID = updatedData.CategoryID
+}
+
+// linkCatalogEntry makes the subscription use the canonical name of entry.
+// Without an entry the service name is kept as cleaned-up free text.
+func (s *Subscription) linkCatalogEntry(entry *catalog.CatalogEntry) {
+	if entry == nil {
+		s.ServiceName = catalog.CleanName(s.ServiceName)
+		s.CatalogEntryID = nil
+		return
+	}
+	s.ServiceName = entry.Name
+	s.CatalogEntryID = &entry.ID
+}
+
+func (s *Subscription) tagNames() []string {
+	var names []string
+	for _, t := range s.Tags {
+		names = append(names, t.Name)
+	}
+	return names
 }
@@ -0,0 +1,107 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	defaultSortKey  = "created_at"
+)
+
+// sortColumns maps the sort keys accepted by the list endpoint to columns.
+var sortColumns = map[string]string{
+	"price":        "price",
+	"start_date":   "start_date",
+	"service_name": "service_name",
+	"created_at":   "created_at",
+}
+
+// ListCursor points right after the last subscription of a page. It records
+// the sort it was issued for, so it cannot be replayed against another order.
+type ListCursor struct {
+	SortBy     string          `json:"s"`
+	Descending bool            `json:"d"`
+	Value      json.RawMessage `json:"v"`
+	ID         uuid.UUID       `json:"id"`
+}
+
+func newListCursor(query *SubscriptionListQueryDTO, last *Subscription) (string, error) {
+	var value any
+	switch query.SortBy {
+	case "price":
+		value = last.Price
+	case "start_date":
+		value = last.StartDate.ToTime()
+	case "service_name":
+		value = last.ServiceName
+	default:
+		value = last.CreatedAt
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	cursor, err := json.Marshal(ListCursor{
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		Value:      raw,
+		ID:         last.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+func decodeListCursor(query *SubscriptionListQueryDTO) (*ListCursor, error) {
+	if query.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil {
+		return nil, apperror.InvalidField("cursor", "invalid cursor")
+	}
+
+	var cursor ListCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, apperror.InvalidField("cursor", "invalid cursor")
+	}
+	if cursor.SortBy != query.SortBy || cursor.Descending != query.Descending {
+		return nil, apperror.InvalidField("cursor", "cursor does not match the requested sort order")
+	}
+	return &cursor, nil
+}
+
+// sortValue decodes the cursor value into the Go type of its sort column.
+func (c *ListCursor) sortValue() (any, error) {
+	switch c.SortBy {
+	case "price":
+		var v int
+		if err := json.Unmarshal(c.Value, &v); err != nil {
+			return nil, apperror.InvalidField("cursor", "invalid cursor")
+		}
+		return v, nil
+	case "service_name":
+		var v string
+		if err := json.Unmarshal(c.Value, &v); err != nil {
+			return nil, apperror.InvalidField("cursor", "invalid cursor")
+		}
+		return v, nil
+	default:
+		var v time.Time
+		if err := json.Unmarshal(c.Value, &v); err != nil {
+			return nil, apperror.InvalidField("cursor", "invalid cursor")
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,104 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	last := &Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Яндекс Плюс",
+		Price:       399,
+		StartDate:   month(2025, time.March),
+		CreatedAt:   time.Date(2025, time.March, 14, 9, 26, 53, 589793238, time.UTC),
+	}
+
+	tests := []struct {
+		sortBy string
+		want   any
+	}{
+		{"price", 399},
+		{"service_name", "Яндекс Плюс"},
+		{"start_date", month(2025, time.March).ToTime()},
+		{"created_at", last.CreatedAt},
+	}
+
+	for _, tt := range tests {
+		for _, descending := range []bool{false, true} {
+			query := &SubscriptionListQueryDTO{SortBy: tt.sortBy, Descending: descending}
+			encoded, err := newListCursor(query, last)
+			if err != nil {
+				t.Fatalf("newListCursor(%s): %v", tt.sortBy, err)
+			}
+
+			query.Cursor = encoded
+			cursor, err := decodeListCursor(query)
+			if err != nil {
+				t.Fatalf("decodeListCursor(%s): %v", tt.sortBy, err)
+			}
+			if cursor.ID != last.ID || cursor.SortBy != tt.sortBy || cursor.Descending != descending {
+				t.Errorf("cursor = %+v, want id %s sorted by %s, descending %v", cursor, last.ID, tt.sortBy, descending)
+			}
+
+			value, err := cursor.sortValue()
+			if err != nil {
+				t.Fatalf("sortValue(%s): %v", tt.sortBy, err)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, ok := value.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("sort value for %s = %v, want %v", tt.sortBy, value, want)
+				}
+			} else if value != tt.want {
+				t.Errorf("sort value for %s = %v, want %v", tt.sortBy, value, tt.want)
+			}
+		}
+	}
+}
+
+func TestDecodeListCursorWithoutCursor(t *testing.T) {
+	cursor, err := decodeListCursor(&SubscriptionListQueryDTO{SortBy: "price"})
+	if cursor != nil || err != nil {
+		t.Errorf("decodeListCursor without a cursor = %+v, %v, want nil, nil", cursor, err)
+	}
+}
+
+func TestDecodeListCursorRejectsOtherSorts(t *testing.T) {
+	encoded, err := newListCursor(&SubscriptionListQueryDTO{SortBy: "price"}, &Subscription{ID: uuid.New(), Price: 399})
+	if err != nil {
+		t.Fatalf("newListCursor: %v", err)
+	}
+
+	for _, query := range []*SubscriptionListQueryDTO{
+		{SortBy: "service_name", Cursor: encoded},
+		{SortBy: "price", Descending: true, Cursor: encoded},
+	} {
+		if _, err := decodeListCursor(query); apperror.KindOf(err) != apperror.KindValidation {
+			t.Errorf("decodeListCursor sorted by %s, descending %v: error = %v, want a validation error", query.SortBy, query.Descending, err)
+		}
+	}
+}
+
+func TestDecodeListCursorRejectsMalformedCursors(t *testing.T) {
+	cursors := []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		// Cursors are unpadded; this 73 byte document always gets padding.
+		base64.StdEncoding.EncodeToString([]byte(`{"s":"price","d":false,"v":1,"id":"` + uuid.NewString() + `"}`)),
+	}
+
+	for _, encoded := range cursors {
+		if _, err := decodeListCursor(&SubscriptionListQueryDTO{SortBy: "price", Cursor: encoded}); apperror.KindOf(err) != apperror.KindValidation {
+			t.Errorf("decodeListCursor(%q) error = %v, want a validation error", encoded, err)
+		}
+	}
+
+	mistyped := &ListCursor{SortBy: "price", Value: []byte(`"399"`)}
+	if _, err := mistyped.sortValue(); apperror.KindOf(err) != apperror.KindValidation {
+		t.Errorf("sortValue of a mistyped value error = %v, want a validation error", err)
+	}
+}
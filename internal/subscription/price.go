@@ -0,0 +1,30 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceChange sets the price of a subscription from EffectiveFrom until the
+// next change. Before its first change a subscription costs its own Price.
+type PriceChange struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_price_changes_month;<-:create" json:"-"`
+	EffectiveFrom  MonthYear `gorm:"not null;uniqueIndex:idx_price_changes_month" json:"effective_from"`
+	Price          int       `gorm:"not null" json:"price"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+}
+
+// priceIn returns the price effective in month. PriceChanges must be ordered
+// by EffectiveFrom, as the repository loads them.
+func (s *Subscription) priceIn(month MonthYear) int {
+	price := s.Price
+	for _, change := range s.PriceChanges {
+		if toMonth(change.EffectiveFrom.ToTime()).ToTime().After(month.ToTime()) {
+			break
+		}
+		price = change.Price
+	}
+	return price
+}
@@ -1,22 +1,59 @@
 package subscription
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tag"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SubscriptionRepository interface {
-	CreateSubscription(subscription *Subscription) (*Subscription, error)
-	GetAllSubscriptionsByUserID(userId uuid.UUID) ([]Subscription, error)
-	GetSubscriptionByID(id uuid.UUID) (*Subscription, error)
-	GetTotalPrice(userId uuid.UUID, serviceName string, from, to time.Time) (int, error)
-	UpdateSubscription(subscription *Subscription) (*Subscription, error)
-	DeleteSubscriptionByID(id uuid.UUID) error
+	WithinTransaction(ctx context.Context, fn func(repo SubscriptionRepository) error) error
+	CreateAuditEntry(ctx context.Context, entry *audit.AuditEntry) error
+	CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)
+	ListSubscriptions(ctx context.Context, query *SubscriptionListQueryDTO, after *ListCursor) ([]Subscription, error)
+	GetSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error)
+	ListUserSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error)
+	ListMemberSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error)
+	GetSubscriptionsInPeriod(ctx context.Context, userId uuid.UUID, service ServiceFilter, from, to time.Time) ([]Subscription, error)
+	ListTrialsEndingBetween(ctx context.Context, userId uuid.UUID, from, to MonthYear) ([]Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)
+	DeleteSubscriptionByID(ctx context.Context, userId, id uuid.UUID, version int) error
+	ListDeletedSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error)
+	GetDeletedSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error)
+	RestoreSubscription(ctx context.Context, userId, id uuid.UUID, version int) (*Subscription, error)
+	PurgeSubscription(ctx context.Context, userId, id uuid.UUID, version int) error
+	ListSubscriptionsDeletedBefore(ctx context.Context, cutoff time.Time) ([]Subscription, error)
+	CreatePriceChange(ctx context.Context, change *PriceChange) (*PriceChange, error)
+	CreateStatusChange(ctx context.Context, change *StatusChange) (*StatusChange, error)
+	DeletePriceChange(ctx context.Context, subscriptionId, id uuid.UUID) error
+	CreateDiscount(ctx context.Context, discount *Discount) (*Discount, error)
+	DeleteDiscount(ctx context.Context, subscriptionId, id uuid.UUID) error
+	EnsureTags(ctx context.Context, userId uuid.UUID, names []string) ([]tag.Tag, error)
+	ReplaceTags(ctx context.Context, subscription *Subscription, tags []tag.Tag) error
+	CreateMember(ctx context.Context, member *SubscriptionMember) (*SubscriptionMember, error)
+	DeleteMember(ctx context.Context, subscriptionId, id uuid.UUID) error
 }
 
+const (
+	// tenantCondition and childTenantCondition restrict queries on
+	// subscriptions and on their child records to one tenant.
+	tenantCondition      = "subscriptions.tenant_id = ?"
+	childTenantCondition = "subscription_id IN (SELECT id FROM subscriptions WHERE tenant_id = ?)"
+)
+
+var errMissingTenant = errors.New("subscription query without a tenant")
+
 type subscriptionRepository struct {
 	db *gorm.DB
 }
@@ -25,67 +62,449 @@ func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
 	return &subscriptionRepository{db: db}
 }
 
+// TrashUserSubscriptions is the user.DeleteCascade that moves the
+// subscriptions of a deleted user to the trash and removes them from the
+// subscriptions shared with them within the deleting transaction, recording
+// an audit entry for each changed subscription.
+func TrashUserSubscriptions(ctx context.Context, tx *gorm.DB, deleted *user.User) error {
+	ctx = tenancy.WithMembership(ctx, deleted.Membership())
+	repo := NewSubscriptionRepository(tx)
+
+	subscriptions, err := repo.ListUserSubscriptions(ctx, deleted.ID)
+	if err != nil {
+		return err
+	}
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if err := repo.DeleteSubscriptionByID(ctx, deleted.ID, subscription.ID, subscription.Version); err != nil {
+			return err
+		}
+		if err := recordChange(ctx, repo, audit.ActionDelete, subscription, nil); err != nil {
+			return err
+		}
+	}
+
+	shared, err := repo.ListMemberSubscriptions(ctx, deleted.ID)
+	if err != nil {
+		return err
+	}
+	for i := range shared {
+		subscription := &shared[i]
+		before := *subscription
+		before.Members = append([]SubscriptionMember(nil), subscription.Members...)
+
+		members := subscription.Members[:0:0]
+		for _, member := range before.Members {
+			if member.UserID != deleted.ID {
+				members = append(members, member)
+				continue
+			}
+			if err := repo.DeleteMember(ctx, subscription.ID, member.ID); err != nil {
+				return err
+			}
+		}
+		subscription.Members = members
+
+		updated, err := repo.UpdateSubscription(ctx, subscription)
+		if err != nil {
+			return err
+		}
+		if err := recordChange(ctx, repo, audit.ActionUpdate, &before, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // -------------------------- repository methods --------------------------
 
-func (r *subscriptionRepository) CreateSubscription(subscription *Subscription) (*Subscription, error) {
-	if err := r.db.Create(subscription).Error; err != nil {
-		return nil, err
+// WithinTransaction runs fn with a repository whose queries share one
+// transaction, committed when fn returns nil and rolled back otherwise.
+func (r *subscriptionRepository) WithinTransaction(ctx context.Context, fn func(repo SubscriptionRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&subscriptionRepository{db: tx})
+	})
+}
+
+func (r *subscriptionRepository) CreateAuditEntry(ctx context.Context, entry *audit.AuditEntry) error {
+	return audit.NewAuditRepository(r.db).CreateEntry(ctx, entry)
+}
+
+func (r *subscriptionRepository) CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error) {
+	if err := r.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		return nil, apperror.Database(err, "subscription")
 	}
 	return subscription, nil
 }
 
-func (r *subscriptionRepository) GetAllSubscriptionsByUserID(userId uuid.UUID) ([]Subscription, error) {
+// ListSubscriptions returns up to query.Limit subscriptions ordered by the
+// requested sort key, with the id as a tie-breaker, starting after the cursor.
+func (r *subscriptionRepository) ListSubscriptions(ctx context.Context, query *SubscriptionListQueryDTO, after *ListCursor) ([]Subscription, error) {
 	var subscriptions []Subscription
-	if err := r.db.Where("user_id = ?", userId).Find(&subscriptions).Error; err != nil {
-		return nil, err
+	db := preloadAssociations(r.scoped(ctx, tenantCondition)).Where("user_id = ?", query.UserID)
+
+	if query.CatalogEntryID != nil {
+		db = db.Where("(service_name ILIKE ? OR catalog_entry_id = ?)", "%"+escapeLike(query.ServiceName)+"%", *query.CatalogEntryID)
+	} else if query.ServiceName != "" {
+		db = db.Where("service_name ILIKE ?", "%"+escapeLike(query.ServiceName)+"%")
+	}
+	if query.MinPrice != nil {
+		db = db.Where("price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("price <= ?", *query.MaxPrice)
+	}
+	if query.ActiveOn != nil {
+		db = db.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", *query.ActiveOn, *query.ActiveOn)
+	}
+	if query.HasEndDate != nil {
+		if *query.HasEndDate {
+			db = db.Where("end_date IS NOT NULL")
+		} else {
+			db = db.Where("end_date IS NULL")
+		}
+	}
+	if query.CategoryID != nil {
+		db = db.Where("category_id = ?", *query.CategoryID)
+	}
+	if query.Tag != "" {
+		db = db.Where(`EXISTS (SELECT 1 FROM subscription_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE st.subscription_id = subscriptions.id AND t.normalized_name = ?)`, tag.NormalizeName(query.Tag))
+	}
+
+	column := sortColumns[query.SortBy]
+	direction, comparison := "ASC", ">"
+	if query.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if after != nil {
+		value, err := after.sortValue()
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, after.ID)
+	}
+
+	err := db.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(query.Limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
 	}
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepository) GetSubscriptionByID(id uuid.UUID) (*Subscription, error) {
+// GetSubscriptionByID returns a subscription owned by the user. Subscriptions
+// of other users are reported as not found.
+func (r *subscriptionRepository) GetSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error) {
 	var subscription Subscription
-	if err := r.db.First(&subscription, id).Error; err != nil {
-		return nil, err
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).Where("user_id = ?", userId).First(&subscription, "id = ?", id).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
 	}
 	return &subscription, nil
 }
 
-func (r *subscriptionRepository) GetTotalPrice(userId uuid.UUID, serviceName string, from, to time.Time) (int, error) {
-	var total sql.NullInt64
-	query := r.db.Model(&Subscription{})
+// ListUserSubscriptions returns every subscription of the user that is not
+// in the trash.
+func (r *subscriptionRepository) ListUserSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).Where("user_id = ?", userId).Order("created_at, id").Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
+	}
+	return subscriptions, nil
+}
+
+// ListMemberSubscriptions returns every subscription shared with the user that
+// is not in the trash.
+func (r *subscriptionRepository) ListMemberSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).
+		Where("id IN (SELECT subscription_id FROM subscription_members WHERE user_id = ?)", userId).
+		Order("created_at, id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
+	}
+	return subscriptions, nil
+}
+
+// GetSubscriptionsInPeriod returns subscriptions that are active for at least
+// one month of [from, to], including the ones the user is a member of, of the
+// service selected by service. A nil userId returns those of every user of
+// the tenant. Zero bounds leave that side of the window open.
+func (r *subscriptionRepository) GetSubscriptionsInPeriod(ctx context.Context, userId uuid.UUID, service ServiceFilter, from, to time.Time) ([]Subscription, error) {
+	var subscriptions []Subscription
+	query := preloadAssociations(r.scoped(ctx, tenantCondition)).Model(&Subscription{})
 
 	if userId != uuid.Nil {
-		query = query.Where("user_id = ?", userId)
+		query = query.Where("user_id = ? OR id IN (SELECT subscription_id FROM subscription_members WHERE user_id = ?)", userId, userId)
 	}
-	if serviceName != "" {
-		query = query.Where("service_name = ?", serviceName)
+	if service.CatalogEntryID != nil {
+		query = query.Where("(catalog_entry_id = ? OR service_name = ?)", *service.CatalogEntryID, service.Name)
+	} else if service.Name != "" {
+		query = query.Where("service_name = ?", service.Name)
 	}
 	if !from.IsZero() {
-		query = query.Where("start_date >= ?", from)
+		query = query.Where("(end_date IS NULL OR end_date >= ?)", from)
 	}
 	if !to.IsZero() {
 		query = query.Where("start_date <= ?", to)
 	}
 
-	if err := query.Select("SUM(price)").Scan(&total).Error; err != nil {
-		return 0, err
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, apperror.Database(err, "subscription")
 	}
-	if !total.Valid {
-		return 0, nil
+	return subscriptions, nil
+}
+
+// ListTrialsEndingBetween returns subscriptions whose last trial month falls
+// within [from, to], ordered by trial end.
+func (r *subscriptionRepository) ListTrialsEndingBetween(ctx context.Context, userId uuid.UUID, from, to MonthYear) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).
+		Where("user_id = ? AND trial_end BETWEEN ? AND ?", userId, from, to).
+		Order("trial_end, service_name, id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
 	}
-	return int(total.Int64), nil
+	return subscriptions, nil
 }
 
-func (r *subscriptionRepository) UpdateSubscription(subscription *Subscription) (*Subscription, error) {
-	if err := r.db.Save(subscription).Error; err != nil {
-		return nil, err
+// UpdateSubscription stores the subscription only if it still has the version
+// it was loaded with, and bumps the version. A concurrent write in between
+// fails the update with a precondition error.
+func (r *subscriptionRepository) UpdateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error) {
+	loadedVersion := subscription.Version
+	subscription.Version++
+
+	result := r.scoped(ctx, tenantCondition).Model(subscription).
+		Where("user_id = ? AND version = ?", subscription.UserID, loadedVersion).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(subscription)
+	if result.Error != nil {
+		subscription.Version = loadedVersion
+		return nil, apperror.Database(result.Error, "subscription")
+	}
+	if result.RowsAffected == 0 {
+		subscription.Version = loadedVersion
+		return nil, apperror.PreconditionFailed("subscription was modified concurrently")
 	}
 	return subscription, nil
 }
 
-func (r *subscriptionRepository) DeleteSubscriptionByID(id uuid.UUID) error {
-	if err := r.db.Delete(&Subscription{}, id).Error; err != nil {
-		return err
+// DeleteSubscriptionByID moves the subscription to the trash, only if it is
+// still at version. Trashed subscriptions are hidden from every other query
+// until they are restored.
+func (r *subscriptionRepository) DeleteSubscriptionByID(ctx context.Context, userId, id uuid.UUID, version int) error {
+	result := r.scoped(ctx, tenantCondition).Model(&Subscription{}).
+		Where("id = ? AND user_id = ? AND version = ?", id, userId, version).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return apperror.Database(result.Error, "subscription")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.PreconditionFailed("subscription was modified concurrently")
+	}
+	return nil
+}
+
+// ListDeletedSubscriptions returns the user's trashed subscriptions, most
+// recently deleted first.
+func (r *subscriptionRepository) ListDeletedSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC, id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
+	}
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) GetDeletedSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error) {
+	var subscription Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		First(&subscription, "id = ?", id).Error
+	if err != nil {
+		return nil, apperror.Database(err, "deleted subscription")
+	}
+	return &subscription, nil
+}
+
+// RestoreSubscription takes a trashed subscription at version out of the trash.
+func (r *subscriptionRepository) RestoreSubscription(ctx context.Context, userId, id uuid.UUID, version int) (*Subscription, error) {
+	result := r.scoped(ctx, tenantCondition).Unscoped().Model(&Subscription{}).
+		Where("id = ? AND user_id = ? AND version = ? AND deleted_at IS NOT NULL", id, userId, version).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, apperror.Database(result.Error, "subscription")
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperror.PreconditionFailed("subscription was modified concurrently")
+	}
+	return r.GetSubscriptionByID(ctx, userId, id)
+}
+
+// PurgeSubscription permanently deletes a trashed subscription at version.
+func (r *subscriptionRepository) PurgeSubscription(ctx context.Context, userId, id uuid.UUID, version int) error {
+	result := r.scoped(ctx, tenantCondition).Unscoped().
+		Where("user_id = ? AND version = ? AND deleted_at IS NOT NULL", userId, version).
+		Delete(&Subscription{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "subscription")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.PreconditionFailed("subscription was modified concurrently")
+	}
+	return nil
+}
+
+// ListSubscriptionsDeletedBefore returns the subscriptions of every user of
+// the tenant, or of every tenant for unscoped contexts, that were trashed
+// before cutoff.
+func (r *subscriptionRepository) ListSubscriptionsDeletedBefore(ctx context.Context, cutoff time.Time) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := preloadAssociations(r.scoped(ctx, tenantCondition)).Unscoped().Where("deleted_at < ?", cutoff).Find(&subscriptions).Error
+	if err != nil {
+		return nil, apperror.Database(err, "subscription")
+	}
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) CreatePriceChange(ctx context.Context, change *PriceChange) (*PriceChange, error) {
+	if err := r.db.WithContext(ctx).Create(change).Error; err != nil {
+		return nil, apperror.Database(err, "price change")
+	}
+	return change, nil
+}
+
+func (r *subscriptionRepository) CreateStatusChange(ctx context.Context, change *StatusChange) (*StatusChange, error) {
+	if err := r.db.WithContext(ctx).Create(change).Error; err != nil {
+		return nil, apperror.Database(err, "status change")
+	}
+	return change, nil
+}
+
+func (r *subscriptionRepository) DeletePriceChange(ctx context.Context, subscriptionId, id uuid.UUID) error {
+	result := r.scoped(ctx, childTenantCondition).Where("subscription_id = ?", subscriptionId).Delete(&PriceChange{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "price change")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("price change not found")
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) CreateDiscount(ctx context.Context, discount *Discount) (*Discount, error) {
+	if err := r.db.WithContext(ctx).Create(discount).Error; err != nil {
+		return nil, apperror.Database(err, "discount")
+	}
+	return discount, nil
+}
+
+func (r *subscriptionRepository) DeleteDiscount(ctx context.Context, subscriptionId, id uuid.UUID) error {
+	result := r.scoped(ctx, childTenantCondition).Where("subscription_id = ?", subscriptionId).Delete(&Discount{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "discount")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("discount not found")
+	}
+	return nil
+}
+
+// EnsureTags returns the user's tags with the given names, creating the
+// missing ones through the repository's connection, so tags created within a
+// transaction are rolled back with it.
+func (r *subscriptionRepository) EnsureTags(ctx context.Context, userId uuid.UUID, names []string) ([]tag.Tag, error) {
+	return tag.NewTagService(tag.NewTagRepository(r.db)).EnsureTags(ctx, userId, names)
+}
+
+// ReplaceTags makes tags the only tags of the subscription. The tags must
+// already exist, and the subscription must have been loaded through a query
+// scoped to the tenant.
+func (r *subscriptionRepository) ReplaceTags(ctx context.Context, subscription *Subscription, tags []tag.Tag) error {
+	err := r.db.WithContext(ctx).Model(subscription).Omit("Tags.*").Association("Tags").Replace(tags)
+	if err != nil {
+		return apperror.Database(err, "tag")
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) CreateMember(ctx context.Context, member *SubscriptionMember) (*SubscriptionMember, error) {
+	if err := r.db.WithContext(ctx).Create(member).Error; err != nil {
+		return nil, apperror.Database(err, "subscription member")
+	}
+	return member, nil
+}
+
+func (r *subscriptionRepository) DeleteMember(ctx context.Context, subscriptionId, id uuid.UUID) error {
+	result := r.scoped(ctx, childTenantCondition).Where("subscription_id = ?", subscriptionId).Delete(&SubscriptionMember{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "subscription member")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("subscription member not found")
 	}
 	return nil
 }
+
+// -------------------------- helpers --------------------------
+
+// scoped returns a handle restricted by condition to the tenant ctx acts in.
+// Queries without a tenant fail, unless ctx was marked by
+// tenancy.WithoutTenant for a task that works across every tenant.
+func (r *subscriptionRepository) scoped(ctx context.Context, condition string) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if tenancy.IsUnscoped(ctx) {
+		return db
+	}
+
+	membership, ok := tenancy.FromContext(ctx)
+	if !ok {
+		db.AddError(errMissingTenant)
+		return db
+	}
+	return db.Where(condition, membership.TenantID)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// preloadAssociations loads the price and status history of queried
+// subscriptions in effective order, their discounts and members in the order
+// they were added, their category and their tags by name.
+func preloadAssociations(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Category").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("normalized_name")
+		}).
+		Preload("PriceChanges", func(db *gorm.DB) *gorm.DB {
+			return db.Order("effective_from")
+		}).
+		Preload("StatusChanges", func(db *gorm.DB) *gorm.DB {
+			return db.Order("effective_from, created_at")
+		}).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		})
+}
@@ -0,0 +1,32 @@
+package subscription
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartTrashRetention purges subscriptions that have been in the trash for
+// longer than retention, once at startup and then every interval.
+func StartTrashRetention(service SubscriptionService, retention, interval time.Duration) {
+	purge := func() {
+		purged, err := service.PurgeExpiredSubscriptions(context.Background(), retention)
+		if err != nil {
+			log.Printf("❌ Failed to purge deleted subscriptions: %v", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("🗑️ Purged %d deleted subscriptions", purged)
+		}
+	}
+
+	go func() {
+		purge()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purge()
+		}
+	}()
+}
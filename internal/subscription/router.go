@@ -3,17 +3,48 @@ package subscription
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
 )
 
-func SubscriptionRouter(subscriptionHandler SubscriptionHandler) chi.Router {
+// SubscriptionRouter serves the subscriptions of the tenant resolved by
+// middleware.ResolveTenant. Every route needs a token scope and a permission
+// that policy grants the user's role.
+func SubscriptionRouter(subscriptionHandler SubscriptionHandler, policy *rbac.Policy) chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", middleware.ErrorWrapper(subscriptionHandler.CreateSubscription))
-	r.Get("/{id}", middleware.ErrorWrapper(subscriptionHandler.GetSubscriptionByID))
-	r.Get("/", middleware.ErrorWrapper(subscriptionHandler.GetAllSubscriptionsByUserID))
-	r.Get("/total-price", middleware.ErrorWrapper(subscriptionHandler.GetTotalPrice))
-	r.Put("/{id}", middleware.ErrorWrapper(subscriptionHandler.UpdateSubscription))
-	r.Delete("/{id}", middleware.ErrorWrapper(subscriptionHandler.DeleteSubscriptionByID))
+	guard := func(scope string, permission rbac.Permission) chi.Router {
+		return r.With(middleware.RequireScope(scope), middleware.RequirePermission(policy, permission))
+	}
+	read := guard(middleware.ScopeRead, rbac.SubscriptionRead)
+	write := guard(middleware.ScopeWrite, rbac.SubscriptionWrite)
+	report := guard(middleware.ScopeRead, rbac.ReportRead)
+	admin := guard(middleware.ScopeRead, rbac.AdminReports)
+
+	write.Post("/", middleware.ErrorWrapper(subscriptionHandler.CreateSubscription))
+	read.Get("/{id}", middleware.ErrorWrapper(subscriptionHandler.GetSubscriptionByID))
+	read.Get("/{id}/history", middleware.ErrorWrapper(subscriptionHandler.GetSubscriptionHistory))
+	write.Post("/{id}/price-changes", middleware.ErrorWrapper(subscriptionHandler.SchedulePriceChange))
+	write.Delete("/{id}/price-changes/{changeId}", middleware.ErrorWrapper(subscriptionHandler.CancelPriceChange))
+	write.Post("/{id}/discounts", middleware.ErrorWrapper(subscriptionHandler.AddDiscount))
+	write.Delete("/{id}/discounts/{discountId}", middleware.ErrorWrapper(subscriptionHandler.RemoveDiscount))
+	write.Post("/{id}/members", middleware.ErrorWrapper(subscriptionHandler.AddMember))
+	write.Delete("/{id}/members/{memberId}", middleware.ErrorWrapper(subscriptionHandler.RemoveMember))
+	write.Post("/{id}/activate", middleware.ErrorWrapper(subscriptionHandler.ActivateSubscription))
+	write.Post("/{id}/pause", middleware.ErrorWrapper(subscriptionHandler.PauseSubscription))
+	write.Post("/{id}/resume", middleware.ErrorWrapper(subscriptionHandler.ResumeSubscription))
+	write.Post("/{id}/cancel", middleware.ErrorWrapper(subscriptionHandler.CancelSubscription))
+	read.Get("/", middleware.ErrorWrapper(subscriptionHandler.ListSubscriptions))
+	report.Get("/total-price", middleware.ErrorWrapper(subscriptionHandler.GetTotalPrice))
+	admin.Get("/organization-total-price", middleware.ErrorWrapper(subscriptionHandler.GetOrganizationTotalPrice))
+	report.Get("/cost-breakdown", middleware.ErrorWrapper(subscriptionHandler.GetCostBreakdown))
+	read.Get("/trials", middleware.ErrorWrapper(subscriptionHandler.ListUpcomingTrialConversions))
+	report.Get("/settlements", middleware.ErrorWrapper(subscriptionHandler.GetSettlement))
+	write.Put("/{id}", middleware.ErrorWrapper(subscriptionHandler.UpdateSubscription))
+	write.Patch("/{id}", middleware.ErrorWrapper(subscriptionHandler.PatchSubscription))
+	write.Delete("/{id}", middleware.ErrorWrapper(subscriptionHandler.DeleteSubscriptionByID))
+	read.Get("/trash", middleware.ErrorWrapper(subscriptionHandler.ListDeletedSubscriptions))
+	write.Post("/trash/{id}/restore", middleware.ErrorWrapper(subscriptionHandler.RestoreSubscription))
+	write.Delete("/trash/{id}", middleware.ErrorWrapper(subscriptionHandler.PurgeSubscription))
 
 	return r
 }
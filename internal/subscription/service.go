@@ -1,68 +1,1065 @@
 package subscription
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/audit"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/catalog"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/category"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/patch"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/user"
 )
 
+// auditResourceType is the resource type of subscription audit entries.
+const auditResourceType = "subscription"
+
 type SubscriptionService interface {
-	CreateSubscription(subscription *SubscriptionCreateDTO) (*Subscription, error)
-	GetAllSubscriptionsByUserID(userId uuid.UUID) ([]Subscription, error)
-	GetSubscriptionByID(id uuid.UUID) (*Subscription, error)
-	GetTotalPrice(userId uuid.UUID, serviceName string, from, to time.Time) (int, error)
-	UpdateSubscription(id uuid.UUID, subscription *SubscriptionUpdateDTO) (*Subscription, error)
-	DeleteSubscriptionByID(id uuid.UUID) error
+	CreateSubscription(ctx context.Context, userId uuid.UUID, subscription *SubscriptionCreateDTO) (*Subscription, error)
+	ListSubscriptions(ctx context.Context, query *SubscriptionListQueryDTO) (*SubscriptionPageDTO, error)
+	GetSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error)
+	GetTotalPrice(ctx context.Context, query CostQueryDTO) (*TotalPriceDTO, error)
+	GetOrganizationTotalPrice(ctx context.Context, query CostQueryDTO) (*OrganizationTotalPriceDTO, error)
+	GetCostBreakdown(ctx context.Context, query CostQueryDTO) (*CostBreakdownDTO, error)
+	UpdateSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, subscription *SubscriptionUpdateDTO) (*Subscription, error)
+	PatchSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, format patch.Format, document []byte) (*Subscription, error)
+	DeleteSubscriptionByID(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) error
+	ListDeletedSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error)
+	RestoreSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) (*Subscription, error)
+	PurgeSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) error
+	PurgeExpiredSubscriptions(ctx context.Context, retention time.Duration) (int64, error)
+	SchedulePriceChange(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, change *PriceChangeCreateDTO) (*Subscription, error)
+	CancelPriceChange(ctx context.Context, userId, id, changeId uuid.UUID, precondition *Precondition) (*Subscription, error)
+	ChangeStatus(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, transition Transition, change *StatusChangeCreateDTO) (*Subscription, error)
+	ListUpcomingTrialConversions(ctx context.Context, userId uuid.UUID, days int) ([]TrialConversionDTO, error)
+	AddDiscount(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, discount *DiscountCreateDTO) (*Subscription, error)
+	RemoveDiscount(ctx context.Context, userId, id, discountId uuid.UUID, precondition *Precondition) (*Subscription, error)
+	AddMember(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, member *MemberCreateDTO) (*Subscription, error)
+	RemoveMember(ctx context.Context, userId, id, memberId uuid.UUID, precondition *Precondition) (*Subscription, error)
+	GetSettlement(ctx context.Context, userId uuid.UUID, month *MonthYear, currencyCode string) (*SettlementDTO, error)
+	GetSubscriptionHistory(ctx context.Context, userId, id uuid.UUID, limit int, cursor string) (*audit.AuditPageDTO, error)
 }
 
 type subscriptionService struct {
-	repo SubscriptionRepository
+	repo       SubscriptionRepository
+	rates      currency.RateProvider
+	audit      audit.AuditService
+	catalog    catalog.CatalogService
+	categories category.CategoryService
+	users      user.UserService
 }
 
-func NewSubscriptionService(repo SubscriptionRepository) SubscriptionService {
-	return &subscriptionService{repo: repo}
+func NewSubscriptionService(repo SubscriptionRepository, rates currency.RateProvider, auditService audit.AuditService, catalogService catalog.CatalogService, categoryService category.CategoryService, userService user.UserService) SubscriptionService {
+	return &subscriptionService{repo: repo, rates: rates, audit: auditService, catalog: catalogService, categories: categoryService, users: userService}
 }
 
 // -------------------------- service methods --------------------------
 
-func (s *subscriptionService) CreateSubscription(subscription *SubscriptionCreateDTO) (*Subscription, error) {
-	subscriptionModel := fromCreateDTOtoSubscription(subscription)
+func (s *subscriptionService) CreateSubscription(ctx context.Context, userId uuid.UUID, subscription *SubscriptionCreateDTO) (*Subscription, error) {
+	if err := validation.Struct(subscription); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.users.GetUserByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.resolveCatalogEntry(ctx, subscription.CatalogEntryID, subscription.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionCategory, err := s.resolveCategory(ctx, userId, subscription.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionModel := fromCreateDTOtoSubscription(owner, subscription, entry)
 	if err := subscriptionModel.Validate(); err != nil {
 		return nil, err
 	}
-	return s.repo.CreateSubscription(subscriptionModel)
+
+	var createdSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		var err error
+		if subscriptionModel.Tags, err = repo.EnsureTags(ctx, userId, subscription.Tags); err != nil {
+			return err
+		}
+		if createdSubscription, err = repo.CreateSubscription(ctx, subscriptionModel); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionCreate, nil, createdSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	createdSubscription.Category = subscriptionCategory
+	return createdSubscription, nil
+}
+
+// ListSubscriptions returns one page of subscriptions and, when more remain,
+// the cursor of the next page.
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, query *SubscriptionListQueryDTO) (*SubscriptionPageDTO, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maxPageSize {
+		query.Limit = maxPageSize
+	}
+	if query.SortBy == "" {
+		query.SortBy = defaultSortKey
+	}
+	if _, ok := sortColumns[query.SortBy]; !ok {
+		return nil, apperror.InvalidField("sort", fmt.Sprintf("invalid sort key %q (expected price, start_date, service_name or created_at)", query.SortBy))
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, apperror.InvalidField("min-price", "min-price cannot be greater than max-price")
+	}
+
+	if query.ServiceName != "" {
+		entry, err := s.catalog.MatchEntry(ctx, query.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			query.CatalogEntryID = &entry.ID
+		}
+	}
+
+	after, err := decodeListCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	pageQuery := *query
+	pageQuery.Limit++
+	subscriptions, err := s.repo.ListSubscriptions(ctx, &pageQuery, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SubscriptionPageDTO{Items: subscriptions}
+	if len(subscriptions) > query.Limit {
+		page.Items = subscriptions[:query.Limit]
+		page.NextCursor, err = newListCursor(query, &page.Items[query.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func (s *subscriptionService) GetSubscriptionByID(ctx context.Context, userId, id uuid.UUID) (*Subscription, error) {
+	return s.repo.GetSubscriptionByID(ctx, userId, id)
+}
+
+// GetTotalPrice sums what subscriptions charge, on their billing cycles, in
+// every month they are active within [from, to]. Shared subscriptions count
+// with the part the user pays. Without to the period ends at the current
+// month. With group-by the total is also split by category or
+// tag; a subscription with several tags counts towards each of them.
+func (s *subscriptionService) GetTotalPrice(ctx context.Context, query CostQueryDTO) (*TotalPriceDTO, error) {
+	return s.totalPrice(ctx, query, nil)
+}
+
+// GetOrganizationTotalPrice sums the full cost of every subscription in the
+// user's tenant, like GetTotalPrice, and splits it by the users that pay for
+// the subscriptions. Shares of members are settled within the organization,
+// so they do not move cost between users here.
+func (s *subscriptionService) GetOrganizationTotalPrice(ctx context.Context, query CostQueryDTO) (*OrganizationTotalPriceDTO, error) {
+	query.Organization = true
+
+	var users []UserCostDTO
+	totalPrice, err := s.totalPrice(ctx, query, func(subscription *Subscription, listPrice, discount int) {
+		users = addUserCost(users, subscription.UserID, listPrice, discount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortUserCosts(users)
+	if users == nil {
+		users = []UserCostDTO{}
+	}
+	return &OrganizationTotalPriceDTO{TotalPriceDTO: *totalPrice, Users: users}, nil
+}
+
+// totalPrice builds the total price of query, passing every converted charge
+// to onCharge as well when it is not nil.
+func (s *subscriptionService) totalPrice(ctx context.Context, query CostQueryDTO, onCharge func(subscription *Subscription, listPrice, discount int)) (*TotalPriceDTO, error) {
+	if query.GroupBy != "" && query.GroupBy != groupByCategory && query.GroupBy != groupByTag {
+		return nil, apperror.InvalidField("group-by", fmt.Sprintf("invalid group-by %q (expected category or tag)", query.GroupBy))
+	}
+
+	var groups []GroupCostDTO
+	breakdown, err := s.costBreakdown(ctx, query, func(subscription *Subscription, listPrice, discount int) {
+		if query.GroupBy != "" {
+			groups = addGroupCost(groups, subscription.costGroups(query.GroupBy), listPrice, discount)
+		}
+		if onCharge != nil {
+			onCharge(subscription, listPrice, discount)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortGroupCosts(groups)
+	return &TotalPriceDTO{
+		TotalPrice: breakdown.Total,
+		ListPrice:  breakdown.ListPrice,
+		Discount:   breakdown.Discount,
+		Currency:   breakdown.Currency,
+		Groups:     groups,
+	}, nil
+}
+
+// GetCostBreakdown splits the total price into monthly buckets and service
+// totals, converted into the requested currency or the user's default one.
+// Without from the period starts at the earliest matching subscription.
+func (s *subscriptionService) GetCostBreakdown(ctx context.Context, query CostQueryDTO) (*CostBreakdownDTO, error) {
+	return s.costBreakdown(ctx, query, nil)
+}
+
+// costBreakdown builds the cost breakdown of query and, when onCharge is not
+// nil, passes it every converted charge along with its subscription.
+func (s *subscriptionService) costBreakdown(ctx context.Context, query CostQueryDTO, onCharge func(subscription *Subscription, listPrice, discount int)) (*CostBreakdownDTO, error) {
+	owner, err := s.users.GetUserByID(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	periodFrom, periodTo, err := resolvePeriod(query.From, query.To, currentMonthIn(owner.Location()))
+	if err != nil {
+		return nil, err
+	}
+
+	targetCurrency := currency.Normalize(query.Currency)
+	if targetCurrency == "" {
+		targetCurrency = owner.DefaultCurrency
+	}
+	if err := currency.Validate(targetCurrency); err != nil {
+		return nil, apperror.InvalidField("currency", err.Error())
+	}
+
+	service, err := s.serviceFilter(ctx, query.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	userId := query.UserID
+	if query.Organization {
+		userId = uuid.Nil
+	}
+	subscriptions, err := s.repo.GetSubscriptionsInPeriod(ctx, userId, service, periodFrom.ToTime(), periodTo.ToTime())
+	if err != nil {
+		return nil, err
+	}
+
+	if query.From.IsZero() {
+		periodFrom = periodTo
+		for i := range subscriptions {
+			start := toMonth(subscriptions[i].StartDate.ToTime())
+			if start.ToTime().Before(periodFrom.ToTime()) {
+				periodFrom = start
+			}
+		}
+	}
+
+	breakdown := newCostBreakdown(periodFrom, periodTo, targetCurrency)
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !query.Organization && subscription.UserID != query.UserID {
+			// The categories and tags of a shared subscription are its owner's.
+			subscription.Category, subscription.Tags = nil, nil
+		}
+		for _, charge := range subscription.charges(periodFrom, periodTo, query.Normalized) {
+			if !query.Organization {
+				charge = subscription.shareOf(query.UserID, charge)
+			}
+			if charge.ListPrice == 0 {
+				continue
+			}
+			listPrice, err := currency.Convert(s.rates, charge.ListPrice, subscription.Currency, targetCurrency, charge.Month.ToTime())
+			if err != nil {
+				return nil, apperror.Validation(err.Error()).WithCode("exchange_rate_unavailable")
+			}
+			discount, err := currency.Convert(s.rates, charge.Discount, subscription.Currency, targetCurrency, charge.Month.ToTime())
+			if err != nil {
+				return nil, apperror.Validation(err.Error()).WithCode("exchange_rate_unavailable")
+			}
+			breakdown.addCharge(subscription.ServiceName, charge.Month, listPrice, discount)
+			if onCharge != nil {
+				onCharge(subscription, listPrice, discount)
+			}
+		}
+	}
+
+	breakdown.sortServices()
+	return breakdown, nil
+}
+
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, subscription *SubscriptionUpdateDTO) (*Subscription, error) {
+	if err := validation.Struct(subscription); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replaceSubscription(ctx, existing, subscription)
 }
 
-func (s *subscriptionService) GetAllSubscriptionsByUserID(userId uuid.UUID) ([]Subscription, error) {
-	return s.repo.GetAllSubscriptionsByUserID(userId)
+// PatchSubscription applies a JSON Merge Patch or JSON Patch document to the
+// update representation of the subscription and stores the result.
+func (s *subscriptionService) PatchSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, format patch.Format, document []byte) (*Subscription, error) {
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(fromSubscriptionToUpdateDTO(existing))
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	patched, err := patch.Apply(format, current, document)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription SubscriptionUpdateDTO
+	if err := common.UnmarshalJSON(patched, &subscription); err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(&subscription); err != nil {
+		return nil, err
+	}
+
+	return s.replaceSubscription(ctx, existing, &subscription)
 }
 
-func (s *subscriptionService) GetSubscriptionByID(id uuid.UUID) (*Subscription, error) {
-	return s.repo.GetSubscriptionByID(id)
+func (s *subscriptionService) DeleteSubscriptionByID(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) error {
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if err := repo.DeleteSubscriptionByID(ctx, userId, id, existing.Version); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionDelete, existing, nil)
+	})
+}
+
+func (s *subscriptionService) ListDeletedSubscriptions(ctx context.Context, userId uuid.UUID) ([]Subscription, error) {
+	return s.repo.ListDeletedSubscriptions(ctx, userId)
+}
+
+func (s *subscriptionService) RestoreSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	deleted, err := s.getMatchingDeletedSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	var restoredSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if restoredSubscription, err = repo.RestoreSubscription(ctx, userId, id, deleted.Version); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionRestore, deleted, restoredSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restoredSubscription, nil
 }
 
-func (s *subscriptionService) GetTotalPrice(userId uuid.UUID, serviceName string, from, to time.Time) (int, error) {
-	return s.repo.GetTotalPrice(userId, serviceName, from, to)
+func (s *subscriptionService) PurgeSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) error {
+	deleted, err := s.getMatchingDeletedSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return err
+	}
+	return s.purgeSubscription(ctx, deleted)
 }
 
-func (s *subscriptionService) UpdateSubscription(id uuid.UUID, subscription *SubscriptionUpdateDTO) (*Subscription, error) {
-	existing, err := s.repo.GetSubscriptionByID(id)
+// PurgeExpiredSubscriptions permanently deletes subscriptions of every tenant
+// that have been in the trash for longer than retention.
+func (s *subscriptionService) PurgeExpiredSubscriptions(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx = tenancy.WithoutTenant(ctx)
+	expired, err := s.repo.ListSubscriptionsDeletedBefore(ctx, time.Now().Add(-retention))
 	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for i := range expired {
+		if err := s.purgeSubscription(ctx, &expired[i]); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// SchedulePriceChange sets a new price from a month on, leaving the price of
+// earlier months untouched. Changes cannot take effect in a past month.
+func (s *subscriptionService) SchedulePriceChange(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, change *PriceChangeCreateDTO) (*Subscription, error) {
+	if err := validation.Struct(change); err != nil {
 		return nil, err
 	}
 
-	existing.UpdateFields(*subscription)
+	effectiveFrom := toMonth(change.EffectiveFrom.ToTime())
+	if !isPriceChangeOpen(effectiveFrom) {
+		return nil, apperror.InvalidField("effective_from", "price changes cannot take effect in a past month")
+	}
+
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+	if !effectiveFrom.ToTime().After(existing.StartDate.ToTime()) {
+		return nil, apperror.InvalidField("effective_from", "price changes must take effect after start_date")
+	}
+
+	before := *existing
+	before.PriceChanges = append([]PriceChange(nil), existing.PriceChanges...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		created, err := repo.CreatePriceChange(ctx, &PriceChange{
+			SubscriptionID: existing.ID,
+			EffectiveFrom:  effectiveFrom,
+			Price:          change.Price,
+		})
+		if err != nil {
+			return err
+		}
+
+		existing.PriceChanges = append(existing.PriceChanges, *created)
+		sort.Slice(existing.PriceChanges, func(i, j int) bool {
+			return existing.PriceChanges[i].EffectiveFrom.ToTime().Before(existing.PriceChanges[j].EffectiveFrom.ToTime())
+		})
+
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// CancelPriceChange removes a price change that has not taken effect yet.
+func (s *subscriptionService) CancelPriceChange(ctx context.Context, userId, id, changeId uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, change := range existing.PriceChanges {
+		if change.ID == changeId {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, apperror.NotFound("price change not found")
+	}
+	if !isPriceChangeOpen(existing.PriceChanges[index].EffectiveFrom) {
+		return nil, apperror.Conflict("price changes of past months cannot be cancelled")
+	}
+
+	before := *existing
+	before.PriceChanges = append([]PriceChange(nil), existing.PriceChanges...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if err := repo.DeletePriceChange(ctx, existing.ID, changeId); err != nil {
+			return err
+		}
+
+		existing.PriceChanges = append(existing.PriceChanges[:index:index], existing.PriceChanges[index+1:]...)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// AddDiscount attaches a discount for a window of months starting no earlier
+// than the subscription. Discounts may cover past months, so promotions can be
+// recorded after the fact.
+func (s *subscriptionService) AddDiscount(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, discount *DiscountCreateDTO) (*Subscription, error) {
+	if err := validation.Struct(discount); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	startMonth := toMonth(discount.StartMonth.ToTime())
+	if startMonth.ToTime().Before(existing.StartDate.ToTime()) {
+		return nil, apperror.InvalidField("start_month", "discounts cannot start before start_date")
+	}
+
+	var endMonth *MonthYear
+	if discount.EndMonth != nil {
+		end := toMonth(discount.EndMonth.ToTime())
+		endMonth = &end
+	}
+
+	before := *existing
+	before.Discounts = append([]Discount(nil), existing.Discounts...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		created, err := repo.CreateDiscount(ctx, &Discount{
+			SubscriptionID: existing.ID,
+			Kind:           discount.Kind,
+			Value:          discount.Value,
+			StartMonth:     startMonth,
+			EndMonth:       endMonth,
+			Description:    discount.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		existing.Discounts = append(existing.Discounts, *created)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// RemoveDiscount detaches a discount; the months it covered are charged at
+// the full price again.
+func (s *subscriptionService) RemoveDiscount(ctx context.Context, userId, id, discountId uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, discount := range existing.Discounts {
+		if discount.ID == discountId {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, apperror.NotFound("discount not found")
+	}
+
+	before := *existing
+	before.Discounts = append([]Discount(nil), existing.Discounts...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if err := repo.DeleteDiscount(ctx, existing.ID, discountId); err != nil {
+			return err
+		}
+
+		existing.Discounts = append(existing.Discounts[:index:index], existing.Discounts[index+1:]...)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// AddMember shares a subscription with another user of the same tenant. The
+// owner keeps paying for it; members owe the owner their part of every charge.
+func (s *subscriptionService) AddMember(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, member *MemberCreateDTO) (*Subscription, error) {
+	if err := validation.Struct(member); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, other := range existing.Members {
+		if other.UserID == member.UserID {
+			return nil, apperror.Conflict("user is already a member of the subscription")
+		}
+	}
+
+	memberUser, err := s.users.GetUserByID(ctx, member.UserID)
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && appErr.Kind == apperror.KindNotFound {
+		return nil, apperror.InvalidField("user_id", "user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if memberUser.TenantID != existing.TenantID {
+		return nil, apperror.InvalidField("user_id", "user is not in the subscription's organization")
+	}
+
+	before := *existing
+	before.Members = append([]SubscriptionMember(nil), existing.Members...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		created, err := repo.CreateMember(ctx, &SubscriptionMember{
+			SubscriptionID: existing.ID,
+			UserID:         member.UserID,
+			Share:          member.Share,
+			Amount:         member.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		existing.Members = append(existing.Members, *created)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// RemoveMember stops sharing a subscription with a member; their part of
+// future and past charges falls back to the others.
+func (s *subscriptionService) RemoveMember(ctx context.Context, userId, id, memberId uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, member := range existing.Members {
+		if member.ID == memberId {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, apperror.NotFound("subscription member not found")
+	}
+
+	before := *existing
+	before.Members = append([]SubscriptionMember(nil), existing.Members...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if err := repo.DeleteMember(ctx, existing.ID, memberId); err != nil {
+			return err
+		}
+
+		existing.Members = append(existing.Members[:index:index], existing.Members[index+1:]...)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// GetSettlement works out who owes whom in a month, the user's current month
+// when month is nil, for the shared subscriptions the user pays for or is a
+// member of. Only debts the user is part of are listed.
+func (s *subscriptionService) GetSettlement(ctx context.Context, userId uuid.UUID, month *MonthYear, currencyCode string) (*SettlementDTO, error) {
+	owner, err := s.users.GetUserByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	settlementMonth := currentMonthIn(owner.Location())
+	if month != nil {
+		settlementMonth = toMonth(month.ToTime())
+	}
+
+	targetCurrency := currency.Normalize(currencyCode)
+	if targetCurrency == "" {
+		targetCurrency = owner.DefaultCurrency
+	}
+	if err := currency.Validate(targetCurrency); err != nil {
+		return nil, apperror.InvalidField("currency", err.Error())
+	}
+
+	subscriptions, err := s.repo.GetSubscriptionsInPeriod(ctx, userId, ServiceFilter{}, settlementMonth.ToTime(), settlementMonth.ToTime())
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := newSettlement(settlementMonth, targetCurrency)
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if len(subscription.Members) == 0 {
+			continue
+		}
+		for _, charge := range subscription.charges(settlementMonth, settlementMonth, false) {
+			split := subscription.splitCharge(charge.ListPrice-charge.Discount, charge.cycleNetPrice)
+			for memberId, amount := range split {
+				if memberId == subscription.UserID || amount == 0 {
+					continue
+				}
+				if memberId != userId && subscription.UserID != userId {
+					continue
+				}
+				converted, err := currency.Convert(s.rates, amount, subscription.Currency, targetCurrency, charge.Month.ToTime())
+				if err != nil {
+					return nil, apperror.Validation(err.Error()).WithCode("exchange_rate_unavailable")
+				}
+				settlement.addShare(subscription, memberId, converted)
+			}
+		}
+	}
+
+	settlement.settle()
+	return settlement, nil
+}
+
+// ChangeStatus applies a lifecycle transition from a month on. Transitions
+// start from the status in that month and are appended after every recorded
+// change, so they cannot take effect in a past month.
+func (s *subscriptionService) ChangeStatus(ctx context.Context, userId, id uuid.UUID, precondition *Precondition, transition Transition, change *StatusChangeCreateDTO) (*Subscription, error) {
+	if err := validation.Struct(change); err != nil {
+		return nil, err
+	}
+
+	effectiveFrom := currentMonth()
+	if change.EffectiveFrom != nil {
+		effectiveFrom = toMonth(change.EffectiveFrom.ToTime())
+	}
+	if effectiveFrom.ToTime().Before(currentMonth().ToTime()) {
+		return nil, apperror.InvalidField("effective_from", "status changes cannot take effect in a past month")
+	}
+
+	existing, err := s.getMatchingSubscription(ctx, userId, id, precondition)
+	if err != nil {
+		return nil, err
+	}
+	status, err := transition.apply(existing.statusIn(effectiveFrom))
+	if err != nil {
+		return nil, apperror.Conflict(err.Error())
+	}
+	if n := len(existing.StatusChanges); n > 0 && effectiveFrom.ToTime().Before(existing.StatusChanges[n-1].EffectiveFrom.ToTime()) {
+		return nil, apperror.InvalidField("effective_from", "status changes cannot take effect before an already scheduled change")
+	}
+
+	before := *existing
+	before.StatusChanges = append([]StatusChange(nil), existing.StatusChanges...)
+
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		created, err := repo.CreateStatusChange(ctx, &StatusChange{
+			SubscriptionID: existing.ID,
+			Status:         status,
+			Reason:         change.Reason,
+			EffectiveFrom:  effectiveFrom,
+		})
+		if err != nil {
+			return err
+		}
+
+		existing.StatusChanges = append(existing.StatusChanges, *created)
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedSubscription, nil
+}
+
+// ListUpcomingTrialConversions returns the trials that become paid within the
+// next days days, soonest first. Trials that were ended early or cancelled are
+// left out.
+func (s *subscriptionService) ListUpcomingTrialConversions(ctx context.Context, userId uuid.UUID, days int) ([]TrialConversionDTO, error) {
+	if days < 0 || days > maxTrialLookaheadDays {
+		return nil, apperror.InvalidField("within-days", fmt.Sprintf("within-days must be between 0 and %d", maxTrialLookaheadDays))
+	}
+
+	owner, err := s.users.GetUserByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(owner.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Trials convert on the first day of a month, so only the months starting
+	// within [today, today+days] can hold a conversion.
+	first := toMonth(today)
+	if today.Day() != 1 {
+		first = first.AddMonths(1)
+	}
+	last := toMonth(today.AddDate(0, 0, days))
+
+	conversions := []TrialConversionDTO{}
+	if last.ToTime().Before(first.ToTime()) {
+		return conversions, nil
+	}
+
+	subscriptions, err := s.repo.ListTrialsEndingBetween(ctx, userId, first.AddMonths(-1), last.AddMonths(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		conversion, ok := subscription.conversionMonth()
+		if !ok {
+			continue
+		}
+		conversions = append(conversions, TrialConversionDTO{
+			SubscriptionID: subscription.ID,
+			ServiceName:    subscription.ServiceName,
+			Price:          subscription.priceIn(conversion),
+			Currency:       subscription.Currency,
+			TrialEnd:       *subscription.TrialEnd,
+			ConvertsOn:     conversion.ToTime().Format("2006-01-02"),
+			DaysLeft:       int(conversion.ToTime().Sub(today).Hours() / 24),
+		})
+	}
+	return conversions, nil
+}
+
+// GetSubscriptionHistory returns the audit entries of a live or trashed
+// subscription of the user, newest first.
+func (s *subscriptionService) GetSubscriptionHistory(ctx context.Context, userId, id uuid.UUID, limit int, cursor string) (*audit.AuditPageDTO, error) {
+	if _, err := s.repo.GetSubscriptionByID(ctx, userId, id); err != nil {
+		if apperror.KindOf(err) != apperror.KindNotFound {
+			return nil, err
+		}
+		if _, err := s.repo.GetDeletedSubscriptionByID(ctx, userId, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.audit.ListEntries(ctx, &audit.AuditQueryDTO{
+		ResourceType: auditResourceType,
+		ResourceID:   id,
+		OwnerID:      userId,
+		Limit:        limit,
+		Cursor:       cursor,
+	})
+}
+
+// -------------------------- helpers --------------------------
+
+// getMatchingSubscription loads a subscription and checks it against the
+// request precondition.
+func (s *subscriptionService) getMatchingSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	existing, err := s.repo.GetSubscriptionByID(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if !precondition.Matches(existing.Version) {
+		return nil, apperror.PreconditionFailed("subscription has been modified since it was fetched")
+	}
+	return existing, nil
+}
+
+func (s *subscriptionService) getMatchingDeletedSubscription(ctx context.Context, userId, id uuid.UUID, precondition *Precondition) (*Subscription, error) {
+	deleted, err := s.repo.GetDeletedSubscriptionByID(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if !precondition.Matches(deleted.Version) {
+		return nil, apperror.PreconditionFailed("subscription has been modified since it was fetched")
+	}
+	return deleted, nil
+}
+
+func (s *subscriptionService) replaceSubscription(ctx context.Context, existing *Subscription, subscription *SubscriptionUpdateDTO) (*Subscription, error) {
+	entry, err := s.resolveCatalogEntry(ctx, replacedCatalogEntryID(existing, subscription), subscription.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionCategory, err := s.resolveCategory(ctx, existing.UserID, subscription.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *existing
+	existing.ReplaceFields(*subscription)
+	existing.linkCatalogEntry(entry)
+	existing.Category = subscriptionCategory
 	if err := existing.Validate(); err != nil {
 		return nil, err
 	}
 
-	updatedSubscription, err := s.repo.UpdateSubscription(existing)
+	var updatedSubscription *Subscription
+	err = s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		tags, err := repo.EnsureTags(ctx, existing.UserID, subscription.Tags)
+		if err != nil {
+			return err
+		}
+		if updatedSubscription, err = repo.UpdateSubscription(ctx, existing); err != nil {
+			return err
+		}
+		if err := repo.ReplaceTags(ctx, updatedSubscription, tags); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionUpdate, &before, updatedSubscription)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return updatedSubscription, nil
 }
 
-func (s *subscriptionService) DeleteSubscriptionByID(id uuid.UUID) error {
-	return s.repo.DeleteSubscriptionByID(id)
+// resolveCatalogEntry returns the catalog entry a subscription refers to:
+// the entry with id when given, otherwise the entry matching serviceName, or
+// nil when none matches.
+func (s *subscriptionService) resolveCatalogEntry(ctx context.Context, id *uuid.UUID, serviceName string) (*catalog.CatalogEntry, error) {
+	if id == nil {
+		return s.catalog.MatchEntry(ctx, serviceName)
+	}
+
+	entry, err := s.catalog.GetEntryByID(ctx, *id)
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && appErr.Kind == apperror.KindNotFound {
+		return nil, apperror.InvalidField("catalog_entry_id", "catalog entry not found")
+	}
+	return entry, err
+}
+
+// isPriceChangeOpen reports whether a price change from month can still be
+// scheduled or cancelled: only past months are settled, so a change from the
+// current month can be undone just as it could be made.
+func isPriceChangeOpen(month MonthYear) bool {
+	return !month.ToTime().Before(currentMonth().ToTime())
+}
+
+// replacedCatalogEntryID returns the catalog entry a replacement links to.
+// Renaming a linked subscription drops the link it still carries from the
+// stored subscription, e.g. when a patch only changes service_name, so the
+// new name is matched against the catalog instead of being overwritten by
+// the old entry's name.
+func replacedCatalogEntryID(existing *Subscription, subscription *SubscriptionUpdateDTO) *uuid.UUID {
+	id := subscription.CatalogEntryID
+	if id == nil || existing.CatalogEntryID == nil || *id != *existing.CatalogEntryID || subscription.ServiceName == "" {
+		return id
+	}
+	if catalog.NormalizeName(subscription.ServiceName) != catalog.NormalizeName(existing.ServiceName) {
+		return nil
+	}
+	return id
+}
+
+// resolveCategory returns the user's category with id, or nil without an id.
+func (s *subscriptionService) resolveCategory(ctx context.Context, userId uuid.UUID, id *uuid.UUID) (*category.Category, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	found, err := s.categories.GetCategoryByID(ctx, userId, *id)
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && appErr.Kind == apperror.KindNotFound {
+		return nil, apperror.InvalidField("category_id", "category not found")
+	}
+	return found, err
+}
+
+// serviceFilter resolves a service-name filter through the catalog, so
+// aliases and differently spelled names find the subscriptions of an entry,
+// including the ones stored before the entry was renamed.
+func (s *subscriptionService) serviceFilter(ctx context.Context, serviceName string) (ServiceFilter, error) {
+	if serviceName == "" {
+		return ServiceFilter{}, nil
+	}
+
+	entry, err := s.catalog.MatchEntry(ctx, serviceName)
+	if err != nil || entry == nil {
+		return ServiceFilter{Name: serviceName}, err
+	}
+	return ServiceFilter{Name: entry.Name, CatalogEntryID: &entry.ID}, nil
+}
+
+func (s *subscriptionService) purgeSubscription(ctx context.Context, deleted *Subscription) error {
+	return s.repo.WithinTransaction(ctx, func(repo SubscriptionRepository) error {
+		if err := repo.PurgeSubscription(ctx, deleted.UserID, deleted.ID, deleted.Version); err != nil {
+			return err
+		}
+		return recordChange(ctx, repo, audit.ActionPurge, deleted, nil)
+	})
+}
+
+// recordChange writes the audit entry of a change through repo, so it is
+// committed together with the change. before is nil for creates and after is
+// nil for deletes.
+func recordChange(ctx context.Context, repo SubscriptionRepository, action audit.Action, before, after *Subscription) error {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	var beforeValue, afterValue interface{}
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	entry, err := audit.NewEntry(ctx, auditResourceType, subject.ID, subject.UserID, action, beforeValue, afterValue)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return repo.CreateAuditEntry(ctx, entry)
+}
+
+// resolvePeriod returns the months of [from, to]; without to the period ends
+// at the current month.
+func resolvePeriod(from, to time.Time, current MonthYear) (MonthYear, MonthYear, error) {
+	var periodFrom MonthYear
+	if !from.IsZero() {
+		periodFrom = toMonth(from)
+	}
+
+	periodTo := current
+	if !to.IsZero() {
+		periodTo = toMonth(to)
+	}
+
+	if periodTo.ToTime().Before(periodFrom.ToTime()) {
+		return periodFrom, periodTo, apperror.InvalidField("from", "from date cannot be after to date")
+	}
+	return periodFrom, periodTo, nil
 }
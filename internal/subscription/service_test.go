@@ -0,0 +1,38 @@
+package subscription
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReplacedCatalogEntryID(t *testing.T) {
+	linked := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name        string
+		stored      *uuid.UUID
+		entryID     *uuid.UUID
+		serviceName string
+		want        *uuid.UUID
+	}{
+		{"unchanged name keeps the link", &linked, &linked, "Netflix", &linked},
+		{"respelled name keeps the link", &linked, &linked, "  netflix ", &linked},
+		{"omitted name keeps the link", &linked, &linked, "", &linked},
+		{"renamed subscription drops the link", &linked, &linked, "Spotify", nil},
+		{"new entry is kept", &linked, &other, "Spotify", &other},
+		{"unlinked subscription links", nil, &other, "Spotify", &other},
+		{"no entry", &linked, nil, "Spotify", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &Subscription{ServiceName: "Netflix", CatalogEntryID: tt.stored}
+			got := replacedCatalogEntryID(existing, &SubscriptionUpdateDTO{ServiceName: tt.serviceName, CatalogEntryID: tt.entryID})
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("replacedCatalogEntryID = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
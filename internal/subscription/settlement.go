@@ -0,0 +1,67 @@
+package subscription
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func newSettlement(month MonthYear, currencyCode string) *SettlementDTO {
+	return &SettlementDTO{
+		Month:    month,
+		Currency: currencyCode,
+		Shares:   []MemberShareDTO{},
+		Debts:    []DebtDTO{},
+	}
+}
+
+// addShare records that userId owes the payer of subscription amount.
+func (s *SettlementDTO) addShare(subscription *Subscription, userId uuid.UUID, amount int) {
+	s.Shares = append(s.Shares, MemberShareDTO{
+		SubscriptionID: subscription.ID,
+		ServiceName:    subscription.ServiceName,
+		UserID:         userId,
+		PayerID:        subscription.UserID,
+		Amount:         amount,
+	})
+}
+
+// settle nets the shares into one debt per pair of users and orders both
+// lists so responses are stable.
+func (s *SettlementDTO) settle() {
+	sort.Slice(s.Shares, func(i, j int) bool {
+		if s.Shares[i].ServiceName != s.Shares[j].ServiceName {
+			return s.Shares[i].ServiceName < s.Shares[j].ServiceName
+		}
+		if s.Shares[i].SubscriptionID != s.Shares[j].SubscriptionID {
+			return s.Shares[i].SubscriptionID.String() < s.Shares[j].SubscriptionID.String()
+		}
+		return s.Shares[i].UserID.String() < s.Shares[j].UserID.String()
+	})
+
+	// balances[pair] is what pair[0] owes pair[1]; negative amounts go the
+	// other way.
+	balances := map[[2]uuid.UUID]int{}
+	for _, share := range s.Shares {
+		from, to, amount := share.UserID, share.PayerID, share.Amount
+		if to.String() < from.String() {
+			from, to, amount = to, from, -amount
+		}
+		balances[[2]uuid.UUID{from, to}] += amount
+	}
+
+	for pair, amount := range balances {
+		switch {
+		case amount > 0:
+			s.Debts = append(s.Debts, DebtDTO{FromUserID: pair[0], ToUserID: pair[1], Amount: amount})
+		case amount < 0:
+			s.Debts = append(s.Debts, DebtDTO{FromUserID: pair[1], ToUserID: pair[0], Amount: -amount})
+		}
+	}
+	sort.Slice(s.Debts, func(i, j int) bool {
+		if s.Debts[i].FromUserID != s.Debts[j].FromUserID {
+			return s.Debts[i].FromUserID.String() < s.Debts[j].FromUserID.String()
+		}
+		return s.Debts[i].ToUserID.String() < s.Debts[j].ToUserID.String()
+	})
+}
@@ -0,0 +1,63 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettleNetsDebtsPerPair(t *testing.T) {
+	paidByA := &Subscription{ServiceName: "Netflix", UserID: memberA}
+	paidByB := &Subscription{ServiceName: "Spotify", UserID: memberB}
+	paidByC := &Subscription{ServiceName: "YouTube", UserID: memberC}
+
+	settlement := newSettlement(month(2025, time.March), "RUB")
+	settlement.addShare(paidByA, memberB, 300)
+	settlement.addShare(paidByA, memberC, 200)
+	settlement.addShare(paidByB, memberA, 500)
+	settlement.addShare(paidByC, memberA, 200)
+	settlement.settle()
+
+	if len(settlement.Shares) != 4 {
+		t.Fatalf("got %d shares, want 4", len(settlement.Shares))
+	}
+	if settlement.Shares[0].ServiceName != "Netflix" || settlement.Shares[3].ServiceName != "YouTube" {
+		t.Errorf("shares are not ordered by service name: %+v", settlement.Shares)
+	}
+
+	// A and B net to A owing B 200, while what A and C owe each other cancels
+	// out.
+	want := []DebtDTO{{FromUserID: memberA, ToUserID: memberB, Amount: 200}}
+	if len(settlement.Debts) != len(want) {
+		t.Fatalf("debts = %+v, want %+v", settlement.Debts, want)
+	}
+	for i := range want {
+		if settlement.Debts[i] != want[i] {
+			t.Errorf("debt %d = %+v, want %+v", i, settlement.Debts[i], want[i])
+		}
+	}
+}
+
+func TestSettleOrdersDebts(t *testing.T) {
+	paidByA := &Subscription{ServiceName: "Netflix", UserID: memberA}
+	paidByB := &Subscription{ServiceName: "Spotify", UserID: memberB}
+
+	settlement := newSettlement(month(2025, time.March), "RUB")
+	settlement.addShare(paidByB, memberC, 150)
+	settlement.addShare(paidByA, memberC, 100)
+	settlement.addShare(paidByA, memberB, 50)
+	settlement.settle()
+
+	want := []DebtDTO{
+		{FromUserID: memberB, ToUserID: memberA, Amount: 50},
+		{FromUserID: memberC, ToUserID: memberA, Amount: 100},
+		{FromUserID: memberC, ToUserID: memberB, Amount: 150},
+	}
+	if len(settlement.Debts) != len(want) {
+		t.Fatalf("debts = %+v, want %+v", settlement.Debts, want)
+	}
+	for i := range want {
+		if settlement.Debts[i] != want[i] {
+			t.Errorf("debt %d = %+v, want %+v", i, settlement.Debts[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package subscription
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status is the lifecycle state of a subscription.
+type Status string
+
+const (
+	StatusTrial     Status = "trial"
+	StatusActive    Status = "active"
+	StatusPaused    Status = "paused"
+	StatusCancelled Status = "cancelled"
+)
+
+// Transition is a named move between lifecycle states.
+type Transition string
+
+const (
+	TransitionActivate Transition = "activate"
+	TransitionPause    Transition = "pause"
+	TransitionResume   Transition = "resume"
+	TransitionCancel   Transition = "cancel"
+)
+
+// transitions is the lifecycle state machine: trial → active ⇄ paused, with
+// cancelled reachable from every other state and final.
+var transitions = map[Transition]struct {
+	from []Status
+	to   Status
+}{
+	TransitionActivate: {from: []Status{StatusTrial}, to: StatusActive},
+	TransitionPause:    {from: []Status{StatusActive}, to: StatusPaused},
+	TransitionResume:   {from: []Status{StatusPaused}, to: StatusActive},
+	TransitionCancel:   {from: []Status{StatusTrial, StatusActive, StatusPaused}, to: StatusCancelled},
+}
+
+// apply returns the status reached by taking transition from current, or an
+// error when the transition is not allowed there.
+func (t Transition) apply(current Status) (Status, error) {
+	rule, ok := transitions[t]
+	if !ok {
+		return "", fmt.Errorf("unknown status transition %q", t)
+	}
+	for _, from := range rule.from {
+		if from == current {
+			return rule.to, nil
+		}
+	}
+	return "", fmt.Errorf("cannot %s a subscription in %s status", t, current)
+}
+
+// isBillable reports whether months in this state are charged. Trial, paused
+// and cancelled months are free.
+func (s Status) isBillable() bool {
+	return s == StatusActive
+}
+
+// StatusChange moves a subscription into Status from EffectiveFrom on. The
+// first change of a subscription records its initial status.
+type StatusChange struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;index;<-:create" json:"-"`
+	Status         Status    `gorm:"not null" json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	EffectiveFrom  MonthYear `gorm:"not null" json:"effective_from"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+}
+
+// statusIn returns the status effective in month. Subscriptions without
+// recorded changes are active, and a trial still running after TrialEnd has
+// converted to active. StatusChanges must be ordered by EffectiveFrom and then
+// CreatedAt, as the repository loads them.
+func (s *Subscription) statusIn(month MonthYear) Status {
+	status := StatusActive
+	for _, change := range s.StatusChanges {
+		if toMonth(change.EffectiveFrom.ToTime()).ToTime().After(month.ToTime()) {
+			break
+		}
+		status = change.Status
+	}
+
+	if status == StatusTrial && s.TrialEnd != nil && month.ToTime().After(s.TrialEnd.ToTime()) {
+		return StatusActive
+	}
+	return status
+}
+
+func (s *Subscription) refreshStatus() {
+	s.Status = s.statusIn(currentMonth())
+}
+
+func (s *Subscription) AfterFind(tx *gorm.DB) error {
+	s.refreshStatus()
+	return nil
+}
+
+func (s *Subscription) AfterSave(tx *gorm.DB) error {
+	s.refreshStatus()
+	return nil
+}
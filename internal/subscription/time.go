@@ -57,3 +57,33 @@ func (m *MonthYear) Scan(value interface{}) error {
 func (m MonthYear) ToTime() time.Time {
 	return time.Time(m)
 }
+
+func (m MonthYear) AddMonths(months int) MonthYear {
+	return MonthYear(m.ToTime().AddDate(0, months, 0))
+}
+
+func (m MonthYear) String() string {
+	return m.ToTime().Format(monthYearLayout)
+}
+
+// toMonth truncates t to the first day of its month in UTC.
+func toMonth(t time.Time) MonthYear {
+	t = t.UTC()
+	return MonthYear(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+func currentMonth() MonthYear {
+	return toMonth(time.Now())
+}
+
+// currentMonthIn returns the month it currently is in location.
+func currentMonthIn(location *time.Location) MonthYear {
+	now := time.Now().In(location)
+	return MonthYear(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// monthsBetween returns the number of whole months from a to b.
+func monthsBetween(a, b MonthYear) int {
+	ta, tb := a.ToTime(), b.ToTime()
+	return (tb.Year()-ta.Year())*12 + int(tb.Month()) - int(ta.Month())
+}
@@ -0,0 +1,45 @@
+package subscription
+
+// Upcoming trial conversions are looked up this many days ahead by default
+// and at most.
+const (
+	defaultTrialLookaheadDays = 7
+	maxTrialLookaheadDays     = 365
+)
+
+// conversionMonth returns the first paid month after the trial, or false when
+// the subscription has no trial end or will not convert because its trial was
+// ended early or it was cancelled.
+func (s *Subscription) conversionMonth() (MonthYear, bool) {
+	if s.TrialEnd == nil {
+		return MonthYear{}, false
+	}
+	trialEnd := toMonth(s.TrialEnd.ToTime())
+	conversion := trialEnd.AddMonths(1)
+	if s.statusIn(trialEnd) != StatusTrial || s.statusIn(conversion) != StatusActive {
+		return MonthYear{}, false
+	}
+	return conversion, true
+}
+
+// billingStart returns the month billing cycles are counted from. That is the
+// start date, or for a subscription that starts with a trial the first month
+// after it, or the month the trial was ended early by an activation.
+func (s *Subscription) billingStart() MonthYear {
+	start := toMonth(s.StartDate.ToTime())
+	if s.TrialEnd == nil {
+		return start
+	}
+
+	billing := toMonth(s.TrialEnd.ToTime()).AddMonths(1)
+	for _, change := range s.StatusChanges {
+		month := toMonth(change.EffectiveFrom.ToTime())
+		if change.Status == StatusActive && month.ToTime().Before(billing.ToTime()) {
+			billing = month
+		}
+	}
+	if billing.ToTime().Before(start.ToTime()) {
+		return start
+	}
+	return billing
+}
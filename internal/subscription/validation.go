@@ -0,0 +1,95 @@
+package subscription
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+)
+
+// Dates outside this range are almost certainly typos.
+var (
+	minMonthYear = MonthYear(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	maxMonthYear = MonthYear(time.Date(2100, time.December, 1, 0, 0, 0, 0, time.UTC))
+)
+
+func init() {
+	validation.Register("monthyear", validateMonthYear,
+		fmt.Sprintf("must be between %s and %s", minMonthYear, maxMonthYear))
+	validation.Register("currency", validateCurrency, "must be a valid ISO 4217 currency code")
+	validation.RegisterMessage("gtestart", "cannot be before start_date")
+	validation.RegisterMessage("trialexclusive", "cannot be combined with trial_end")
+	validation.RegisterMessage("trialstatus", "must be trial when a trial is set")
+	validation.RegisterMessage("gtestartmonth", "cannot be before start_month")
+	validation.RegisterMessage("maxpercent", "cannot exceed 100 for percent discounts")
+	validation.RegisterMessage("shareexclusive", "cannot be combined with share")
+
+	validation.RegisterStruct(validateCreateDTO, SubscriptionCreateDTO{})
+	validation.RegisterStruct(validateUpdateDTO, SubscriptionUpdateDTO{})
+	validation.RegisterStruct(validateDiscountDTO, DiscountCreateDTO{})
+	validation.RegisterStruct(validateMemberDTO, MemberCreateDTO{})
+}
+
+func validateMonthYear(fl validator.FieldLevel) bool {
+	m, ok := fl.Field().Interface().(MonthYear)
+	if !ok {
+		return false
+	}
+	return !m.ToTime().Before(minMonthYear.ToTime()) && !m.ToTime().After(maxMonthYear.ToTime())
+}
+
+func validateCurrency(fl validator.FieldLevel) bool {
+	return currency.IsValid(currency.Normalize(fl.Field().String()))
+}
+
+func validateCreateDTO(sl validator.StructLevel) {
+	dto := sl.Current().Interface().(SubscriptionCreateDTO)
+	if dto.EndDate != nil && dto.EndDate.ToTime().Before(dto.StartDate.ToTime()) {
+		sl.ReportError(dto.EndDate, "end_date", "EndDate", "gtestart", "")
+	}
+	if dto.TrialEnd != nil && dto.TrialEnd.ToTime().Before(dto.StartDate.ToTime()) {
+		sl.ReportError(dto.TrialEnd, "trial_end", "TrialEnd", "gtestart", "")
+	}
+	if dto.TrialMonths != nil && dto.TrialEnd != nil {
+		sl.ReportError(dto.TrialMonths, "trial_months", "TrialMonths", "trialexclusive", "")
+	}
+	if (dto.TrialMonths != nil || dto.TrialEnd != nil) && dto.Status == StatusActive {
+		sl.ReportError(dto.Status, "status", "Status", "trialstatus", "")
+	}
+}
+
+func validateUpdateDTO(sl validator.StructLevel) {
+	dto := sl.Current().Interface().(SubscriptionUpdateDTO)
+	if dto.EndDate != nil && dto.EndDate.ToTime().Before(dto.StartDate.ToTime()) {
+		sl.ReportError(dto.EndDate, "end_date", "EndDate", "gtestart", "")
+	}
+	if dto.TrialEnd != nil && dto.TrialEnd.ToTime().Before(dto.StartDate.ToTime()) {
+		sl.ReportError(dto.TrialEnd, "trial_end", "TrialEnd", "gtestart", "")
+	}
+}
+
+func validateDiscountDTO(sl validator.StructLevel) {
+	dto := sl.Current().Interface().(DiscountCreateDTO)
+	if dto.EndMonth != nil && dto.EndMonth.ToTime().Before(dto.StartMonth.ToTime()) {
+		sl.ReportError(dto.EndMonth, "end_month", "EndMonth", "gtestartmonth", "")
+	}
+	if dto.Kind == DiscountPercent && dto.Value > 100 {
+		sl.ReportError(dto.Value, "value", "Value", "maxpercent", "")
+	}
+}
+
+func validateMemberDTO(sl validator.StructLevel) {
+	dto := sl.Current().Interface().(MemberCreateDTO)
+	if dto.UserID == uuid.Nil {
+		sl.ReportError(dto.UserID, "user_id", "UserID", "required", "")
+	}
+	if dto.Share == nil && dto.Amount == nil {
+		sl.ReportError(dto.Share, "share", "Share", "required_without", "")
+	}
+	if dto.Share != nil && dto.Amount != nil {
+		sl.ReportError(dto.Amount, "amount", "Amount", "shareexclusive", "")
+	}
+}
@@ -0,0 +1,5 @@
+package tag
+
+type TagDTO struct {
+	Name string `json:"name" validate:"required,notblank,max=50"`
+}
@@ -0,0 +1,193 @@
+package tag
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type TagHandler struct {
+	tagService TagService
+}
+
+func NewTagHandler(tagService TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// -------------------- handler methods ----------------
+
+// CreateTag godoc
+// @Summary      Create tag
+// @Description  Creates a tag for the authenticated user's subscriptions; names are unique per user ignoring case
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        tag      body      TagDTO  true  "Tag name"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/tags [post]
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var tag TagDTO
+	if err := common.DecodeJSON(w, r, &tag); err != nil {
+		return err
+	}
+
+	createdTag, err := h.tagService.CreateTag(r.Context(), userId, &tag)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "tag created",
+		Data:    createdTag,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListTags godoc
+// @Summary      List tags
+// @Description  Returns the authenticated user's tags ordered by name
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/tags [get]
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.tagService.ListTags(r.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    tags,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RenameTag godoc
+// @Summary      Rename tag
+// @Description  Renames one of the authenticated user's tags
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Tag ID"
+// @Param        tag      body      TagDTO  true  "New tag name"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/tags/{id} [put]
+func (h *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseTagID(r)
+	if err != nil {
+		return err
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var tag TagDTO
+	if err := common.DecodeJSON(w, r, &tag); err != nil {
+		return err
+	}
+
+	renamedTag, err := h.tagService.RenameTag(r.Context(), userId, id, &tag)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "tag renamed",
+		Data:    renamedTag,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// DeleteTag godoc
+// @Summary      Delete tag
+// @Description  Deletes one of the authenticated user's tags; it is removed from every subscription
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "Tag ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/tags/{id} [delete]
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseTagID(r)
+	if err != nil {
+		return err
+	}
+
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.tagService.DeleteTag(r.Context(), userId, id); err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "tag deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseTagID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "tag ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid tag ID format")
+	}
+	return id, nil
+}
@@ -0,0 +1,31 @@
+package tag
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a free-form label on a user's subscriptions, e.g. "family" or
+// "work". A subscription can have any number of tags.
+type Tag struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name;<-:create" json:"user_id"`
+	Name           string    `gorm:"not null" json:"name"`
+	NormalizedName string    `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"-"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Rename sets the name, trimmed and with runs of whitespace collapsed.
+// Names are unique per user ignoring case.
+func (t *Tag) Rename(name string) {
+	t.Name = strings.Join(strings.Fields(name), " ")
+	t.NormalizedName = NormalizeName(t.Name)
+}
+
+// NormalizeName returns the key tag names are compared by.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,96 @@
+package tag
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *Tag) (*Tag, error)
+	CreateMissingTags(ctx context.Context, tags []Tag) error
+	ListTags(ctx context.Context, userId uuid.UUID) ([]Tag, error)
+	GetTagByID(ctx context.Context, userId, id uuid.UUID) (*Tag, error)
+	GetTagsByNormalizedNames(ctx context.Context, userId uuid.UUID, names []string) ([]Tag, error)
+	UpdateTag(ctx context.Context, tag *Tag) (*Tag, error)
+	DeleteTag(ctx context.Context, userId, id uuid.UUID) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *tagRepository) CreateTag(ctx context.Context, tag *Tag) (*Tag, error) {
+	if err := r.db.WithContext(ctx).Create(tag).Error; err != nil {
+		return nil, apperror.Database(err, "tag")
+	}
+	return tag, nil
+}
+
+// CreateMissingTags inserts the tags whose name the user does not have yet
+// and skips the others.
+func (r *tagRepository) CreateMissingTags(ctx context.Context, tags []Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	return apperror.Database(err, "tag")
+}
+
+func (r *tagRepository) ListTags(ctx context.Context, userId uuid.UUID) ([]Tag, error) {
+	var tags []Tag
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("normalized_name").Find(&tags).Error; err != nil {
+		return nil, apperror.Database(err, "tag")
+	}
+	return tags, nil
+}
+
+// GetTagByID returns a tag owned by the user. Tags of other users are
+// reported as not found.
+func (r *tagRepository) GetTagByID(ctx context.Context, userId, id uuid.UUID) (*Tag, error) {
+	var tag Tag
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).First(&tag, "id = ?", id).Error; err != nil {
+		return nil, apperror.Database(err, "tag")
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) GetTagsByNormalizedNames(ctx context.Context, userId uuid.UUID, names []string) ([]Tag, error) {
+	var tags []Tag
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND normalized_name IN ?", userId, names).
+		Order("normalized_name").
+		Find(&tags).Error
+	if err != nil {
+		return nil, apperror.Database(err, "tag")
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) UpdateTag(ctx context.Context, tag *Tag) (*Tag, error) {
+	if err := r.db.WithContext(ctx).Save(tag).Error; err != nil {
+		return nil, apperror.Database(err, "tag")
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag from the user's subscriptions and deletes it.
+func (r *tagRepository) DeleteTag(ctx context.Context, userId, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&Tag{}, "id = ?", id)
+	if result.Error != nil {
+		return apperror.Database(result.Error, "tag")
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("tag not found")
+	}
+	return nil
+}
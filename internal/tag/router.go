@@ -0,0 +1,27 @@
+package tag
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// TagRouter serves the tags of the requesting user behind
+// middleware.ResolveTenant; reading them needs rbac.TagRead and changing them
+// rbac.TagWrite.
+func TagRouter(tagHandler TagHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	guard := func(scope string, permission rbac.Permission) chi.Router {
+		return r.With(middleware.RequireScope(scope), middleware.RequirePermission(policy, permission))
+	}
+	read := guard(middleware.ScopeRead, rbac.TagRead)
+	write := guard(middleware.ScopeWrite, rbac.TagWrite)
+
+	write.Post("/", middleware.ErrorWrapper(tagHandler.CreateTag))
+	read.Get("/", middleware.ErrorWrapper(tagHandler.ListTags))
+	write.Put("/{id}", middleware.ErrorWrapper(tagHandler.RenameTag))
+	write.Delete("/{id}", middleware.ErrorWrapper(tagHandler.DeleteTag))
+
+	return r
+}
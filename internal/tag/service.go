@@ -0,0 +1,94 @@
+package tag
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+)
+
+type TagService interface {
+	CreateTag(ctx context.Context, userId uuid.UUID, tag *TagDTO) (*Tag, error)
+	ListTags(ctx context.Context, userId uuid.UUID) ([]Tag, error)
+	RenameTag(ctx context.Context, userId, id uuid.UUID, tag *TagDTO) (*Tag, error)
+	DeleteTag(ctx context.Context, userId, id uuid.UUID) error
+	EnsureTags(ctx context.Context, userId uuid.UUID, names []string) ([]Tag, error)
+}
+
+type tagService struct {
+	repo TagRepository
+}
+
+func NewTagService(repo TagRepository) TagService {
+	return &tagService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+func (s *tagService) CreateTag(ctx context.Context, userId uuid.UUID, tag *TagDTO) (*Tag, error) {
+	if err := validation.Struct(tag); err != nil {
+		return nil, err
+	}
+
+	model := &Tag{ID: uuid.New(), UserID: userId}
+	model.Rename(tag.Name)
+	return s.repo.CreateTag(ctx, model)
+}
+
+func (s *tagService) ListTags(ctx context.Context, userId uuid.UUID) ([]Tag, error) {
+	return s.repo.ListTags(ctx, userId)
+}
+
+func (s *tagService) RenameTag(ctx context.Context, userId, id uuid.UUID, tag *TagDTO) (*Tag, error) {
+	if err := validation.Struct(tag); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetTagByID(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Rename(tag.Name)
+	return s.repo.UpdateTag(ctx, existing)
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, userId, id uuid.UUID) error {
+	return s.repo.DeleteTag(ctx, userId, id)
+}
+
+// EnsureTags returns the user's tags with the given names, creating the ones
+// that do not exist yet. Names differing only in case or whitespace resolve
+// to the same tag.
+func (s *tagService) EnsureTags(ctx context.Context, userId uuid.UUID, names []string) ([]Tag, error) {
+	var wanted []Tag
+	var normalized []string
+	for _, name := range names {
+		tag := Tag{ID: uuid.New(), UserID: userId}
+		tag.Rename(name)
+		if tag.NormalizedName == "" || contains(normalized, tag.NormalizedName) {
+			continue
+		}
+		wanted = append(wanted, tag)
+		normalized = append(normalized, tag.NormalizedName)
+	}
+	if len(wanted) == 0 {
+		return []Tag{}, nil
+	}
+
+	if err := s.repo.CreateMissingTags(ctx, wanted); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTagsByNormalizedNames(ctx, userId, normalized)
+}
+
+// -------------------------- helpers --------------------------
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,5 @@
+package tenant
+
+type TenantDTO struct {
+	Name string `json:"name" validate:"required,notblank,max=255"`
+}
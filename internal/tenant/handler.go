@@ -0,0 +1,124 @@
+package tenant
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type TenantHandler struct {
+	tenantService TenantService
+}
+
+func NewTenantHandler(tenantService TenantService) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService}
+}
+
+// -------------------- handler methods ----------------
+
+// CreateTenant godoc
+// @Summary      Create organization
+// @Description  Creates an organization without users; add its first owner with POST /api/organizations/{id}/users. Requires the system scope
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        organization  body      TenantDTO  true  "Organization name"
+// @Success      201  {object}  common.Response
+// @Security     BearerAuth
+// @Router       /api/organizations [post]
+func (h *TenantHandler) CreateTenant(w http.ResponseWriter, r *http.Request) error {
+	var tenant TenantDTO
+	if err := common.DecodeJSON(w, r, &tenant); err != nil {
+		return err
+	}
+
+	createdTenant, err := h.tenantService.CreateTenant(r.Context(), &tenant)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "organization created",
+		Data:    createdTenant,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetCurrentTenant godoc
+// @Summary      Get current organization
+// @Description  Returns the organization of the authenticated user
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/organizations/current [get]
+func (h *TenantHandler) GetCurrentTenant(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := h.tenantService.GetTenantByID(r.Context(), membership.TenantID)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    tenant,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// RenameCurrentTenant godoc
+// @Summary      Rename current organization
+// @Description  Renames the organization of the authenticated user, who must be one of its owners
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        organization  body      TenantDTO  true  "Organization name"
+// @Param        user-id       query     string     false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      403  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/organizations/current [put]
+func (h *TenantHandler) RenameCurrentTenant(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	var tenant TenantDTO
+	if err := common.DecodeJSON(w, r, &tenant); err != nil {
+		return err
+	}
+
+	updatedTenant, err := h.tenantService.RenameTenant(r.Context(), membership.TenantID, &tenant)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "organization renamed",
+		Data:    updatedTenant,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
@@ -0,0 +1,23 @@
+package tenant
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is an organization, such as a household or a company, that owns
+// users and their subscriptions. Every user belongs to exactly one tenant;
+// users created without one get a personal tenant with the user's ID.
+type Tenant struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Rename sets the name, trimmed and with runs of whitespace collapsed.
+func (t *Tenant) Rename(name string) {
+	t.Name = strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,47 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"gorm.io/gorm"
+)
+
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant *Tenant) (*Tenant, error)
+	GetTenantByID(ctx context.Context, id uuid.UUID) (*Tenant, error)
+	UpdateTenant(ctx context.Context, tenant *Tenant) (*Tenant, error)
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *tenantRepository) CreateTenant(ctx context.Context, tenant *Tenant) (*Tenant, error) {
+	if err := r.db.WithContext(ctx).Create(tenant).Error; err != nil {
+		return nil, apperror.Database(err, "organization")
+	}
+	return tenant, nil
+}
+
+func (r *tenantRepository) GetTenantByID(ctx context.Context, id uuid.UUID) (*Tenant, error) {
+	var tenant Tenant
+	if err := r.db.WithContext(ctx).First(&tenant, "id = ?", id).Error; err != nil {
+		return nil, apperror.Database(err, "organization")
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) UpdateTenant(ctx context.Context, tenant *Tenant) (*Tenant, error) {
+	if err := r.db.WithContext(ctx).Save(tenant).Error; err != nil {
+		return nil, apperror.Database(err, "organization")
+	}
+	return tenant, nil
+}
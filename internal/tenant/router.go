@@ -0,0 +1,33 @@
+package tenant
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// TenantRouter serves the organization of the authenticated user, resolved by
+// resolveTenant, and mounts members under it. Creating organizations and
+// adding users to them, mounted as users, require the system scope; reading
+// and changing one permissions the policy grants the user's role.
+func TenantRouter(tenantHandler TenantHandler, policy *rbac.Policy, resolveTenant func(http.Handler) http.Handler, members, users http.Handler) chi.Router {
+	r := chi.NewRouter()
+
+	r.With(middleware.RequireScope(middleware.ScopeSystem)).Post("/", middleware.ErrorWrapper(tenantHandler.CreateTenant))
+	r.Mount("/{id}/users", users)
+
+	r.Route("/current", func(r chi.Router) {
+		r.Use(resolveTenant)
+
+		read := r.With(middleware.RequireScope(middleware.ScopeRead), middleware.RequirePermission(policy, rbac.OrganizationRead))
+		write := r.With(middleware.RequireScope(middleware.ScopeWrite), middleware.RequirePermission(policy, rbac.OrganizationWrite))
+
+		read.Get("/", middleware.ErrorWrapper(tenantHandler.GetCurrentTenant))
+		write.Put("/", middleware.ErrorWrapper(tenantHandler.RenameCurrentTenant))
+		r.Mount("/members", members)
+	})
+
+	return r
+}
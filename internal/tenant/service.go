@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+)
+
+type TenantService interface {
+	CreateTenant(ctx context.Context, tenant *TenantDTO) (*Tenant, error)
+	GetTenantByID(ctx context.Context, id uuid.UUID) (*Tenant, error)
+	RenameTenant(ctx context.Context, id uuid.UUID, tenant *TenantDTO) (*Tenant, error)
+}
+
+type tenantService struct {
+	repo TenantRepository
+}
+
+func NewTenantService(repo TenantRepository) TenantService {
+	return &tenantService{repo: repo}
+}
+
+// -------------------------- service methods --------------------------
+
+// CreateTenant creates an organization without users; users join it when
+// they are created with its ID.
+func (s *tenantService) CreateTenant(ctx context.Context, tenant *TenantDTO) (*Tenant, error) {
+	if err := validation.Struct(tenant); err != nil {
+		return nil, err
+	}
+
+	model := &Tenant{ID: uuid.New()}
+	model.Rename(tenant.Name)
+	return s.repo.CreateTenant(ctx, model)
+}
+
+func (s *tenantService) GetTenantByID(ctx context.Context, id uuid.UUID) (*Tenant, error) {
+	return s.repo.GetTenantByID(ctx, id)
+}
+
+func (s *tenantService) RenameTenant(ctx context.Context, id uuid.UUID, tenant *TenantDTO) (*Tenant, error) {
+	if err := validation.Struct(tenant); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetTenantByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Rename(tenant.Name)
+	return s.repo.UpdateTenant(ctx, existing)
+}
@@ -0,0 +1,58 @@
+package user
+
+import (
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+)
+
+// UserCreateDTO creates a user in an organization with role, member by
+// default. The id should be the subject of the user's tokens; one is
+// generated when it is omitted.
+type UserCreateDTO struct {
+	ID              *uuid.UUID   `json:"id,omitempty"`
+	Name            string       `json:"name" validate:"required,notblank,max=255"`
+	Email           string       `json:"email" validate:"required,email,max=255"`
+	DefaultCurrency string       `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+	Timezone        string       `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Role            tenancy.Role `json:"role,omitempty" validate:"omitempty,oneof=owner admin member viewer"`
+}
+
+// UserUpdateDTO replaces a user's profile and settings entirely. Omitted
+// settings are reset to their defaults.
+type UserUpdateDTO struct {
+	Name            string `json:"name" validate:"required,notblank,max=255"`
+	Email           string `json:"email" validate:"required,email,max=255"`
+	DefaultCurrency string `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+	Timezone        string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+// UserSettingsDTO replaces a user's settings. Omitted settings are reset to
+// their defaults.
+type UserSettingsDTO struct {
+	DefaultCurrency string `json:"default_currency,omitempty" validate:"omitempty,len=3"`
+	Timezone        string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+// RoleUpdateDTO changes the role of a member of an organization.
+type RoleUpdateDTO struct {
+	Role tenancy.Role `json:"role" validate:"required,oneof=owner admin member viewer"`
+}
+
+// fromCreateDTOtoUser builds the user described by dto as a member of the
+// tenant tenantId.
+func fromCreateDTOtoUser(tenantId uuid.UUID, dto *UserCreateDTO) *User {
+	user := &User{ID: uuid.New(), TenantID: tenantId, Role: dto.Role}
+	if user.Role == "" {
+		user.Role = tenancy.RoleMember
+	}
+	if dto.ID != nil {
+		user.ID = *dto.ID
+	}
+	user.ReplaceFields(UserUpdateDTO{
+		Name:            dto.Name,
+		Email:           dto.Email,
+		DefaultCurrency: dto.DefaultCurrency,
+		Timezone:        dto.Timezone,
+	})
+	return user
+}
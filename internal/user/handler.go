@@ -0,0 +1,437 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+)
+
+type UserHandler struct {
+	userService UserService
+}
+
+func NewUserHandler(userService UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// -------------------- handler methods ----------------
+
+// CreateUser godoc
+// @Summary      Create user
+// @Description  Adds a user to the organization with a role no higher than the caller's; the ID should be the subject of the user's tokens and is generated when omitted
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user     body      UserCreateDTO  true  "User data"
+// @Param        user-id  query     string         false "User ID, required for API key clients"
+// @Success      201  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users [post]
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	var user UserCreateDTO
+	if err := common.DecodeJSON(w, r, &user); err != nil {
+		return err
+	}
+
+	createdUser, err := h.userService.CreateUser(r.Context(), membership, &user)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "user created",
+		Data:    createdUser,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ProvisionUser godoc
+// @Summary      Provision organization user
+// @Description  Adds a user with any role to an organization, e.g. its first owner; requires the system scope
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string         true  "Organization ID"
+// @Param        user  body      UserCreateDTO  true  "User data"
+// @Success      201  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Router       /api/organizations/{id}/users [post]
+func (h *UserHandler) ProvisionUser(w http.ResponseWriter, r *http.Request) error {
+	tenantId, err := parseOrganizationID(r)
+	if err != nil {
+		return err
+	}
+
+	var user UserCreateDTO
+	if err := common.DecodeJSON(w, r, &user); err != nil {
+		return err
+	}
+
+	createdUser, err := h.userService.ProvisionUser(r.Context(), tenantId, &user)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "user created",
+		Data:    createdUser,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListUsers godoc
+// @Summary      List users
+// @Description  Returns every user of the organization that has not been deleted, oldest first
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users [get]
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.userService.ListMembers(r.Context(), membership.TenantID)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    users,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetUserByID godoc
+// @Summary      Get user
+// @Description  Returns a user of the organization by ID
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "User ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users/{id} [get]
+func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseUserID(r)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userService.GetMember(r.Context(), membership.TenantID, id)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    user,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// UpdateUser godoc
+// @Summary      Replace user
+// @Description  Replaces the profile and settings of a user of the organization; omitted settings are reset to their defaults
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string         true  "User ID"
+// @Param        user     body      UserUpdateDTO  true  "User data"
+// @Param        user-id  query     string         false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users/{id} [put]
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var user UserUpdateDTO
+	if err := common.DecodeJSON(w, r, &user); err != nil {
+		return err
+	}
+
+	updatedUser, err := h.userService.UpdateUser(r.Context(), membership.TenantID, id, &user)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "user updated",
+		Data:    updatedUser,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// DeleteUser godoc
+// @Summary      Delete user
+// @Description  Deletes a user of the organization, moves their subscriptions to the trash and removes them from the subscriptions shared with them; only owners remove owners, and the last owner cannot be removed
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string  true  "User ID"
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      403  {object}  common.Response
+// @Failure      404  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users/{id} [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), membership, id); err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "user deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// GetCurrentUser godoc
+// @Summary      Get current user
+// @Description  Returns the profile and settings of the authenticated user
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users/me [get]
+func (h *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    user,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// UpdateCurrentUserSettings godoc
+// @Summary      Replace current user settings
+// @Description  Replaces the default currency and timezone of the authenticated user; omitted settings are reset to their defaults
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        settings  body      UserSettingsDTO  true  "User settings"
+// @Param        user-id   query     string           false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/users/me/settings [put]
+func (h *UserHandler) UpdateCurrentUserSettings(w http.ResponseWriter, r *http.Request) error {
+	userId, err := middleware.RequestUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var settings UserSettingsDTO
+	if err := common.DecodeJSON(w, r, &settings); err != nil {
+		return err
+	}
+
+	updatedUser, err := h.userService.UpdateSettings(r.Context(), userId, &settings)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "settings updated",
+		Data:    updatedUser,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ListMembers godoc
+// @Summary      List organization members
+// @Description  Returns the users of the authenticated user's organization with their roles, oldest first
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        user-id  query     string  false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/organizations/current/members [get]
+func (h *UserHandler) ListMembers(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.userService.ListMembers(r.Context(), membership.TenantID)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Data:    users,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// ChangeMemberRole godoc
+// @Summary      Change member role
+// @Description  Changes the role of a member of the authenticated user's organization; requires the admin role, and the owner role to grant or revoke owner
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string         true  "User ID"
+// @Param        role     body      RoleUpdateDTO  true  "New role"
+// @Param        user-id  query     string         false "User ID, required for API key clients"
+// @Success      200  {object}  common.Response
+// @Failure      403  {object}  common.Response
+// @Failure      409  {object}  common.Response
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api/organizations/current/members/{id}/role [put]
+func (h *UserHandler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) error {
+	membership, err := middleware.RequestTenant(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseUserID(r)
+	if err != nil {
+		return err
+	}
+
+	var role RoleUpdateDTO
+	if err := common.DecodeJSON(w, r, &role); err != nil {
+		return err
+	}
+
+	updatedUser, err := h.userService.ChangeRole(r.Context(), membership, id, &role)
+	if err != nil {
+		return err
+	}
+
+	response := common.Response{
+		Success: true,
+		Message: "role updated",
+		Data:    updatedUser,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// -------------------- helpers ----------------
+
+func parseUserID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "user ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid user ID format")
+	}
+	return id, nil
+}
+
+func parseOrganizationID(r *http.Request) (uuid.UUID, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return uuid.Nil, apperror.InvalidField("id", "organization ID is required")
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, apperror.InvalidField("id", "invalid organization ID format")
+	}
+	return id, nil
+}
@@ -0,0 +1,114 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/currency"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// DefaultTimezone is the timezone of users that have not chosen one.
+const DefaultTimezone = "UTC"
+
+// User owns subscriptions. Its settings are the defaults of the user's new
+// subscriptions and cost reports.
+type User struct {
+	ID    uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;<-:create" json:"id"`
+	Name  string    `gorm:"not null" json:"name"`
+	Email *string   `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL" json:"email,omitempty"`
+	// TenantID is the organization the user belongs to, and Role what the
+	// user may do there.
+	TenantID uuid.UUID      `gorm:"type:uuid;not null;index;<-:create" json:"tenant_id"`
+	Tenant   *tenant.Tenant `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Role     tenancy.Role   `gorm:"not null;default:owner" json:"role"`
+	// DefaultCurrency is used for new subscriptions without a currency and as
+	// the currency of cost reports.
+	DefaultCurrency string `gorm:"type:char(3);not null;default:RUB" json:"default_currency"`
+	// Timezone decides which day and month it currently is for the user.
+	Timezone  string         `gorm:"not null;default:UTC" json:"timezone"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;<-:create" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (u *User) Validate() error {
+	var fields []apperror.FieldError
+
+	if strings.TrimSpace(u.Name) == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name is required"})
+	}
+	fields = append(fields, u.settingsErrors()...)
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields[0].Message, fields...)
+	}
+	return nil
+}
+
+// ValidateSettings checks only the settings, so users without a name, such
+// as the ones created for existing subscription owners on upgrade, can still
+// change them.
+func (u *User) ValidateSettings() error {
+	if fields := u.settingsErrors(); len(fields) > 0 {
+		return apperror.Validation(fields[0].Message, fields...)
+	}
+	return nil
+}
+
+func (u *User) settingsErrors() []apperror.FieldError {
+	var fields []apperror.FieldError
+
+	if err := currency.Validate(u.DefaultCurrency); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "default_currency", Message: err.Error()})
+	}
+
+	if _, err := time.LoadLocation(u.Timezone); err != nil || u.Timezone == "Local" {
+		fields = append(fields, apperror.FieldError{Field: "timezone", Message: "unknown timezone " + u.Timezone})
+	}
+	return fields
+}
+
+// ReplaceFields overwrites the profile and settings with data.
+func (u *User) ReplaceFields(data UserUpdateDTO) {
+	u.Name = strings.Join(strings.Fields(data.Name), " ")
+	email := NormalizeEmail(data.Email)
+	u.Email = &email
+	u.ApplySettings(UserSettingsDTO{DefaultCurrency: data.DefaultCurrency, Timezone: data.Timezone})
+}
+
+// ApplySettings overwrites the settings with data. Omitted settings are reset
+// to their defaults.
+func (u *User) ApplySettings(data UserSettingsDTO) {
+	u.DefaultCurrency = currency.Normalize(data.DefaultCurrency)
+	if u.DefaultCurrency == "" {
+		u.DefaultCurrency = currency.DefaultCode
+	}
+	u.Timezone = strings.TrimSpace(data.Timezone)
+	if u.Timezone == "" {
+		u.Timezone = DefaultTimezone
+	}
+}
+
+// Membership returns the tenant of the user and the user's role there.
+func (u *User) Membership() tenancy.Membership {
+	return tenancy.Membership{TenantID: u.TenantID, Role: u.Role}
+}
+
+// Location returns the user's timezone, or UTC when it cannot be loaded.
+func (u *User) Location() *time.Location {
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// NormalizeEmail returns the form emails are stored and compared in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
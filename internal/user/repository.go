@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *User) (*User, error)
+	ListUsersByTenant(ctx context.Context, tenantId uuid.UUID) ([]User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	UpdateUser(ctx context.Context, user *User) (*User, error)
+	UpdateRole(ctx context.Context, user *User) (*User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+}
+
+// DeleteCascade handles what belongs to a user that is being deleted. It runs
+// in the deleting transaction tx, so a failure keeps the user.
+type DeleteCascade func(ctx context.Context, tx *gorm.DB, deleted *User) error
+
+type userRepository struct {
+	db       *gorm.DB
+	cascades []DeleteCascade
+}
+
+func NewUserRepository(db *gorm.DB, cascades ...DeleteCascade) UserRepository {
+	return &userRepository{db: db, cascades: cascades}
+}
+
+// -------------------------- repository methods --------------------------
+
+func (r *userRepository) CreateUser(ctx context.Context, user *User) (*User, error) {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, apperror.Database(err, "user")
+	}
+	return user, nil
+}
+
+func (r *userRepository) ListUsersByTenant(ctx context.Context, tenantId uuid.UUID) ([]User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantId).Order("created_at, id").Find(&users).Error; err != nil {
+		return nil, apperror.Database(err, "user")
+	}
+	return users, nil
+}
+
+// GetUserByID returns a user that has not been deleted.
+func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	var user User
+	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
+		return nil, apperror.Database(err, "user")
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *User) (*User, error) {
+	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
+		return nil, apperror.Database(err, "user")
+	}
+	return user, nil
+}
+
+// UpdateRole stores the role of the user unless that leaves the user's
+// tenant without an owner. Role changes within a tenant are serialized by
+// locking the tenant row.
+func (r *userRepository) UpdateRole(ctx context.Context, user *User) (*User, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT 1 FROM tenants WHERE id = ? FOR UPDATE", user.TenantID).Error; err != nil {
+			return apperror.Database(err, "organization")
+		}
+
+		if err := tx.Model(user).Update("role", user.Role).Error; err != nil {
+			return apperror.Database(err, "user")
+		}
+
+		var owners int64
+		err := tx.Model(&User{}).Where("tenant_id = ? AND role = ?", user.TenantID, tenancy.RoleOwner).Count(&owners).Error
+		if err != nil {
+			return apperror.Database(err, "user")
+		}
+		if owners == 0 {
+			return apperror.Conflict("organization must keep at least one owner")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser soft-deletes the user and runs the delete cascades in the same
+// transaction, unless that leaves the user's tenant without an owner. Like
+// role changes, deletions within a tenant are serialized by locking the
+// tenant row.
+func (r *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var deleted User
+		if err := tx.First(&deleted, "id = ?", id).Error; err != nil {
+			return apperror.Database(err, "user")
+		}
+		if err := tx.Exec("SELECT 1 FROM tenants WHERE id = ? FOR UPDATE", deleted.TenantID).Error; err != nil {
+			return apperror.Database(err, "organization")
+		}
+		if err := tx.Delete(&deleted).Error; err != nil {
+			return apperror.Database(err, "user")
+		}
+
+		if deleted.Role == tenancy.RoleOwner {
+			var owners int64
+			err := tx.Model(&User{}).Where("tenant_id = ? AND role = ?", deleted.TenantID, tenancy.RoleOwner).Count(&owners).Error
+			if err != nil {
+				return apperror.Database(err, "user")
+			}
+			if owners == 0 {
+				return apperror.Conflict("organization must keep at least one owner")
+			}
+		}
+
+		for _, cascade := range r.cascades {
+			if err := cascade(ctx, tx, &deleted); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/middleware"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/rbac"
+)
+
+// UserRouter lets users read their own profile and change their settings,
+// and organization admins manage the users of their organization. It must be
+// mounted behind middleware.ResolveTenant; every route needs a token scope and
+// a permission policy grants the user's role.
+func UserRouter(userHandler UserHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	guard := func(scope string, permission rbac.Permission) chi.Router {
+		return r.With(middleware.RequireScope(scope), middleware.RequirePermission(policy, permission))
+	}
+	read := guard(middleware.ScopeRead, rbac.UserRead)
+	write := guard(middleware.ScopeWrite, rbac.UserWrite)
+	admin := guard(middleware.ScopeAdmin, rbac.AdminUsers)
+
+	read.Get("/me", middleware.ErrorWrapper(userHandler.GetCurrentUser))
+	write.Put("/me/settings", middleware.ErrorWrapper(userHandler.UpdateCurrentUserSettings))
+
+	admin.Post("/", middleware.ErrorWrapper(userHandler.CreateUser))
+	admin.Get("/", middleware.ErrorWrapper(userHandler.ListUsers))
+	admin.Get("/{id}", middleware.ErrorWrapper(userHandler.GetUserByID))
+	admin.Put("/{id}", middleware.ErrorWrapper(userHandler.UpdateUser))
+	admin.Delete("/{id}", middleware.ErrorWrapper(userHandler.DeleteUser))
+
+	return r
+}
+
+// ProvisionRouter adds users to the organization in the URL. It serves the
+// operators of the service and requires the system scope.
+func ProvisionRouter(userHandler UserHandler) chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequireScope(middleware.ScopeSystem))
+
+	r.Post("/", middleware.ErrorWrapper(userHandler.ProvisionUser))
+
+	return r
+}
+
+// MemberRouter serves the members of the authenticated user's organization.
+// It must be mounted behind middleware.ResolveTenant; listing members and
+// changing roles require permissions the policy grants the user's role.
+func MemberRouter(userHandler UserHandler, policy *rbac.Policy) chi.Router {
+	r := chi.NewRouter()
+
+	read := r.With(middleware.RequireScope(middleware.ScopeRead), middleware.RequirePermission(policy, rbac.MemberRead))
+	admin := r.With(middleware.RequireScope(middleware.ScopeWrite), middleware.RequirePermission(policy, rbac.AdminMembers))
+
+	read.Get("/", middleware.ErrorWrapper(userHandler.ListMembers))
+	admin.Put("/{id}/role", middleware.ErrorWrapper(userHandler.ChangeMemberRole))
+
+	return r
+}
@@ -0,0 +1,189 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/apperror"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/tenancy"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/common/validation"
+	"github.com/qwerty2265/go-chi-subscription-manager/internal/tenant"
+)
+
+type UserService interface {
+	CreateUser(ctx context.Context, actor tenancy.Membership, user *UserCreateDTO) (*User, error)
+	ProvisionUser(ctx context.Context, tenantId uuid.UUID, user *UserCreateDTO) (*User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	UpdateUser(ctx context.Context, tenantId, id uuid.UUID, user *UserUpdateDTO) (*User, error)
+	UpdateSettings(ctx context.Context, id uuid.UUID, settings *UserSettingsDTO) (*User, error)
+	DeleteUser(ctx context.Context, actor tenancy.Membership, id uuid.UUID) error
+	Membership(ctx context.Context, id uuid.UUID) (tenancy.Membership, error)
+	ListMembers(ctx context.Context, tenantId uuid.UUID) ([]User, error)
+	GetMember(ctx context.Context, tenantId, id uuid.UUID) (*User, error)
+	ChangeRole(ctx context.Context, actor tenancy.Membership, id uuid.UUID, role *RoleUpdateDTO) (*User, error)
+}
+
+type userService struct {
+	repo    UserRepository
+	tenants tenant.TenantService
+}
+
+func NewUserService(repo UserRepository, tenantService tenant.TenantService) UserService {
+	return &userService{repo: repo, tenants: tenantService}
+}
+
+// -------------------------- service methods --------------------------
+
+// CreateUser adds a user to the actor's tenant with a role no higher than the
+// actor's own.
+func (s *userService) CreateUser(ctx context.Context, actor tenancy.Membership, user *UserCreateDTO) (*User, error) {
+	if err := validation.Struct(user); err != nil {
+		return nil, err
+	}
+
+	model := fromCreateDTOtoUser(actor.TenantID, user)
+	if err := checkGrant(actor, model.Role); err != nil {
+		return nil, err
+	}
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateUser(ctx, model)
+}
+
+// ProvisionUser adds a user to the tenant tenantId with any role. It is meant
+// for the operators of the service, who set up organizations and their first
+// owners.
+func (s *userService) ProvisionUser(ctx context.Context, tenantId uuid.UUID, user *UserCreateDTO) (*User, error) {
+	if err := validation.Struct(user); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.tenants.GetTenantByID(ctx, tenantId); err != nil {
+		return nil, err
+	}
+
+	model := fromCreateDTOtoUser(tenantId, user)
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateUser(ctx, model)
+}
+
+func (s *userService) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	return s.repo.GetUserByID(ctx, id)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, tenantId, id uuid.UUID, user *UserUpdateDTO) (*User, error) {
+	if err := validation.Struct(user); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetMember(ctx, tenantId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.ReplaceFields(*user)
+	if err := existing.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateUser(ctx, existing)
+}
+
+func (s *userService) UpdateSettings(ctx context.Context, id uuid.UUID, settings *UserSettingsDTO) (*User, error) {
+	if err := validation.Struct(settings); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.ApplySettings(*settings)
+	if err := existing.ValidateSettings(); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateUser(ctx, existing)
+}
+
+// DeleteUser removes a user of the actor's tenant. Only owners may remove an
+// owner, and the last owner cannot be removed.
+func (s *userService) DeleteUser(ctx context.Context, actor tenancy.Membership, id uuid.UUID) error {
+	existing, err := s.GetMember(ctx, actor.TenantID, id)
+	if err != nil {
+		return err
+	}
+	if existing.Role == tenancy.RoleOwner && !actor.Role.AtLeast(tenancy.RoleOwner) {
+		return apperror.Forbidden("only owners can remove an owner")
+	}
+	return s.repo.DeleteUser(ctx, id)
+}
+
+// Membership returns the tenant of a user and the user's role there. Users
+// that do not exist belong to no tenant and may not act in any.
+func (s *userService) Membership(ctx context.Context, id uuid.UUID) (tenancy.Membership, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		if apperror.KindOf(err) == apperror.KindNotFound {
+			return tenancy.Membership{}, apperror.Forbidden("user is not a member of any organization")
+		}
+		return tenancy.Membership{}, err
+	}
+	return user.Membership(), nil
+}
+
+func (s *userService) ListMembers(ctx context.Context, tenantId uuid.UUID) ([]User, error) {
+	return s.repo.ListUsersByTenant(ctx, tenantId)
+}
+
+// GetMember returns a user of the tenant tenantId. Users of other tenants are
+// reported as not found.
+func (s *userService) GetMember(ctx context.Context, tenantId, id uuid.UUID) (*User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantId {
+		return nil, apperror.NotFound("user not found")
+	}
+	return user, nil
+}
+
+// ChangeRole sets the role of a user in the actor's tenant. Only owners may
+// grant or revoke the owner role, and the last owner cannot give it up.
+func (s *userService) ChangeRole(ctx context.Context, actor tenancy.Membership, id uuid.UUID, role *RoleUpdateDTO) (*User, error) {
+	if err := validation.Struct(role); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetMember(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Role == tenancy.RoleOwner && !actor.Role.AtLeast(tenancy.RoleOwner) {
+		return nil, apperror.Forbidden("only owners can grant or revoke the owner role")
+	}
+	if err := checkGrant(actor, role.Role); err != nil {
+		return nil, err
+	}
+
+	existing.Role = role.Role
+	return s.repo.UpdateRole(ctx, existing)
+}
+
+// -------------------------- helpers --------------------------
+
+// checkGrant makes sure actor does not hand out a role above their own, and
+// that only owners grant the owner role.
+func checkGrant(actor tenancy.Membership, role tenancy.Role) error {
+	if role == tenancy.RoleOwner && !actor.Role.AtLeast(tenancy.RoleOwner) {
+		return apperror.Forbidden("only owners can grant or revoke the owner role")
+	}
+	if !actor.Role.AtLeast(role) {
+		return apperror.Forbidden("cannot grant a role above your own")
+	}
+	return nil
+}